@@ -0,0 +1,42 @@
+package auth
+
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleEditor    = "editor"
+	RoleAdmin     = "admin"
+)
+
+// Permission names an action a route can be restricted to
+type Permission string
+
+const (
+	PermissionWriteReviews    Permission = "reviews:write"
+	PermissionModerateReviews Permission = "reviews:moderate"
+	PermissionWriteCatalog    Permission = "catalog:write"
+	PermissionManageRoles     Permission = "roles:manage"
+	PermissionReadAudit       Permission = "audit:read"
+)
+
+// rolePermissions grants each role its permissions. Moderators and editors
+// extend the user role in different directions; admins hold everything.
+var rolePermissions = map[string][]Permission{
+	RoleUser:      {PermissionWriteReviews},
+	RoleModerator: {PermissionWriteReviews, PermissionModerateReviews},
+	RoleEditor:    {PermissionWriteReviews, PermissionWriteCatalog},
+	RoleAdmin:     {PermissionWriteReviews, PermissionModerateReviews, PermissionWriteCatalog, PermissionManageRoles, PermissionReadAudit},
+}
+
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+func HasPermission(role string, permission Permission) bool {
+	for _, granted := range rolePermissions[role] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Cladkoewka/movie-manager/internal/config"
+)
+
+const (
+	BackendRedis  = "redis"
+	BackendMemory = "memory"
+	BackendNone   = "none"
+)
+
+// ErrCacheMiss is returned by GetCache when the key is absent or expired
+var ErrCacheMiss = errors.New("cache miss")
+
+// Cache is a key-value store for serialized responses
+type Cache interface {
+	GetCache(ctx context.Context, key string, dest interface{}) error
+	SetCache(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	// GetGeneration returns the current generation number for the given namespace.
+	// Cache keys built from the generation become unreachable once it is bumped.
+	GetGeneration(ctx context.Context, namespace string) (int64, error)
+	// BumpGeneration invalidates every cache entry built for the namespace
+	BumpGeneration(ctx context.Context, namespace string) error
+}
+
+// New creates the cache backend selected in the config
+func New(cfg *config.Config) (Cache, error) {
+	switch cfg.CacheBackend {
+	case BackendRedis, "":
+		return NewRedisService(cfg.RedisAddr, cfg.RedisPassword, cfg.RedisDB), nil
+	case BackendMemory:
+		return NewMemoryCache(cfg.CacheMemorySize), nil
+	case BackendNone:
+		return NewNoopCache(), nil
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q", cfg.CacheBackend)
+	}
+}
+
+// GenerateCacheKey builds a stable key from the prefix and the JSON form of params
+func GenerateCacheKey(prefix string, params interface{}) (string, error) {
+	bytes, err := json.Marshal(params)
+	if err != nil {
+		return "", err
+	}
+	hash := sha1.Sum(bytes)
+	return fmt.Sprintf("%s:%s", prefix, hex.EncodeToString(hash[:])), nil
+}
+
+func generationKey(namespace string) string {
+	return fmt.Sprintf("%s:generation", namespace)
+}
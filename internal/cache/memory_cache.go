@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// MemoryCache is an in-process LRU cache with per-entry expiration.
+// Values are stored as JSON so callers see the same behaviour as with Redis.
+type MemoryCache struct {
+	mu          sync.Mutex
+	capacity    int
+	order       *list.List
+	entries     map[string]*list.Element
+	generations map[string]int64
+}
+
+type memoryEntry struct {
+	key       string
+	data      []byte
+	expiresAt time.Time
+}
+
+func NewMemoryCache(capacity int) *MemoryCache {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &MemoryCache{
+		capacity:    capacity,
+		order:       list.New(),
+		entries:     make(map[string]*list.Element),
+		generations: make(map[string]int64),
+	}
+}
+
+func (m *MemoryCache) GetCache(ctx context.Context, key string, dest interface{}) error {
+	m.mu.Lock()
+	element, ok := m.entries[key]
+	if !ok {
+		m.mu.Unlock()
+		return ErrCacheMiss
+	}
+	entry := element.Value.(*memoryEntry)
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		m.removeElement(element)
+		m.mu.Unlock()
+		return ErrCacheMiss
+	}
+	m.order.MoveToFront(element)
+	data := entry.data
+	m.mu.Unlock()
+
+	return json.Unmarshal(data, dest)
+}
+
+func (m *MemoryCache) SetCache(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	var expiresAt time.Time
+	if expiration > 0 {
+		expiresAt = time.Now().Add(expiration)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if element, ok := m.entries[key]; ok {
+		entry := element.Value.(*memoryEntry)
+		entry.data = data
+		entry.expiresAt = expiresAt
+		m.order.MoveToFront(element)
+		return nil
+	}
+
+	m.entries[key] = m.order.PushFront(&memoryEntry{key: key, data: data, expiresAt: expiresAt})
+	for m.order.Len() > m.capacity {
+		m.removeElement(m.order.Back())
+	}
+	return nil
+}
+
+func (m *MemoryCache) GetGeneration(ctx context.Context, namespace string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.generations[namespace], nil
+}
+
+func (m *MemoryCache) BumpGeneration(ctx context.Context, namespace string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.generations[namespace]++
+	return nil
+}
+
+func (m *MemoryCache) removeElement(element *list.Element) {
+	m.order.Remove(element)
+	delete(m.entries, element.Value.(*memoryEntry).key)
+}
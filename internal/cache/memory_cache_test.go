@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMemoryCacheRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(10)
+
+	type payload struct {
+		Title  string
+		Rating float64
+	}
+	if err := c.SetCache(ctx, "movie", payload{Title: "Heat", Rating: 8.3}, 0); err != nil {
+		t.Fatalf("SetCache: %v", err)
+	}
+
+	var got payload
+	if err := c.GetCache(ctx, "movie", &got); err != nil {
+		t.Fatalf("GetCache: %v", err)
+	}
+	if got.Title != "Heat" || got.Rating != 8.3 {
+		t.Errorf("GetCache = %+v, want Heat 8.3", got)
+	}
+
+	if err := c.GetCache(ctx, "missing", &got); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("GetCache(missing) error = %v, want ErrCacheMiss", err)
+	}
+}
+
+func TestMemoryCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(2)
+
+	mustSet(t, c, "a", 1)
+	mustSet(t, c, "b", 2)
+	// Reading a makes b the least recently used entry
+	var value int
+	if err := c.GetCache(ctx, "a", &value); err != nil {
+		t.Fatalf("GetCache(a): %v", err)
+	}
+	mustSet(t, c, "c", 3)
+
+	if err := c.GetCache(ctx, "b", &value); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("GetCache(b) error = %v, want ErrCacheMiss after eviction", err)
+	}
+	for key, want := range map[string]int{"a": 1, "c": 3} {
+		if err := c.GetCache(ctx, key, &value); err != nil || value != want {
+			t.Errorf("GetCache(%s) = %d, %v, want %d", key, value, err, want)
+		}
+	}
+}
+
+func TestMemoryCacheOverwriteDoesNotEvict(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(2)
+
+	mustSet(t, c, "a", 1)
+	mustSet(t, c, "b", 2)
+	mustSet(t, c, "a", 10)
+
+	var value int
+	if err := c.GetCache(ctx, "a", &value); err != nil || value != 10 {
+		t.Errorf("GetCache(a) = %d, %v, want 10", value, err)
+	}
+	if err := c.GetCache(ctx, "b", &value); err != nil || value != 2 {
+		t.Errorf("GetCache(b) = %d, %v, want 2", value, err)
+	}
+}
+
+func TestMemoryCacheExpiresEntries(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(10)
+
+	if err := c.SetCache(ctx, "short", 1, 20*time.Millisecond); err != nil {
+		t.Fatalf("SetCache: %v", err)
+	}
+	mustSet(t, c, "forever", 2)
+
+	var value int
+	if err := c.GetCache(ctx, "short", &value); err != nil {
+		t.Fatalf("GetCache before expiry: %v", err)
+	}
+	time.Sleep(40 * time.Millisecond)
+
+	if err := c.GetCache(ctx, "short", &value); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("GetCache after expiry error = %v, want ErrCacheMiss", err)
+	}
+	if err := c.GetCache(ctx, "forever", &value); err != nil || value != 2 {
+		t.Errorf("GetCache(forever) = %d, %v, want 2", value, err)
+	}
+}
+
+func TestMemoryCacheGenerationInvalidation(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(10)
+
+	key := func() string {
+		generation, err := c.GetGeneration(ctx, "movies")
+		if err != nil {
+			t.Fatalf("GetGeneration: %v", err)
+		}
+		return fmt.Sprintf("movies:%d:list", generation)
+	}
+
+	mustSet(t, c, key(), "cached list")
+	if err := c.BumpGeneration(ctx, "movies"); err != nil {
+		t.Fatalf("BumpGeneration: %v", err)
+	}
+
+	var value string
+	if err := c.GetCache(ctx, key(), &value); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("GetCache after bump error = %v, want ErrCacheMiss", err)
+	}
+
+	if generation, _ := c.GetGeneration(ctx, "genres"); generation != 0 {
+		t.Errorf("GetGeneration(genres) = %d, want other namespaces untouched", generation)
+	}
+}
+
+func mustSet(t *testing.T, c *MemoryCache, key string, value interface{}) {
+	t.Helper()
+	if err := c.SetCache(context.Background(), key, value, 0); err != nil {
+		t.Fatalf("SetCache(%s): %v", key, err)
+	}
+}
@@ -2,13 +2,9 @@ package cache
 
 import (
 	"context"
-	"crypto/sha1"
-	"encoding/hex"
 	"encoding/json"
-	"fmt"
 	"time"
 
-	"github.com/Cladkoewka/movie-manager/internal/model/dto"
 	"github.com/go-redis/redis/v8"
 )
 
@@ -16,17 +12,17 @@ type RedisService struct {
 	client *redis.Client
 }
 
-func NewRedisService() *RedisService {
-	client := newRedisClient()
+func NewRedisService(addr, password string, db int) *RedisService {
+	client := newRedisClient(addr, password, db)
 
 	return &RedisService{client: client}
 }
 
-func newRedisClient() *redis.Client {
+func newRedisClient(addr, password string, db int) *redis.Client {
 	client := redis.NewClient(&redis.Options{
-		Addr:     "localhost:6380",
-		Password: "", 
-		DB:       0,  
+		Addr:     addr,
+		Password: password,
+		DB:       db,
 	})
 
 	return client
@@ -34,6 +30,9 @@ func newRedisClient() *redis.Client {
 
 func (r *RedisService) GetCache(ctx context.Context, key string, dest interface{}) error {
 	data, err := r.client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return ErrCacheMiss
+	}
 	if err != nil {
 		return err
 	}
@@ -45,16 +44,19 @@ func (r *RedisService) SetCache(ctx context.Context, key string, value interface
 	data, err := json.Marshal(value)
 	if err != nil {
 		return err
-	}	
+	}
 
 	return r.client.Set(ctx, key, data, expiration).Err()
 }
 
-func (r *RedisService) GenerateCacheKey(prefix string, params dto.MovieQueryParams) (string, error) {
-	bytes, err := json.Marshal(params)
-	if err != nil {
-		return "", err
+func (r *RedisService) GetGeneration(ctx context.Context, namespace string) (int64, error) {
+	generation, err := r.client.Get(ctx, generationKey(namespace)).Int64()
+	if err == redis.Nil {
+		return 0, nil
 	}
-	hash := sha1.Sum(bytes) 
-	return fmt.Sprintf("%s:%s", prefix, hex.EncodeToString(hash[:])), nil
-}
\ No newline at end of file
+	return generation, err
+}
+
+func (r *RedisService) BumpGeneration(ctx context.Context, namespace string) error {
+	return r.client.Incr(ctx, generationKey(namespace)).Err()
+}
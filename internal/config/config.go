@@ -3,6 +3,9 @@ package config
 import (
 	//"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 	//"github.com/joho/godotenv"
 )
 
@@ -12,6 +15,25 @@ type Config struct {
 	DBHost     string
 	DBPort     string
 	DBName     string
+
+	CacheBackend    string
+	CacheMemorySize int
+	RedisAddr       string
+	RedisPassword   string
+	RedisDB         int
+
+	JWTSecret     string
+	JWTAccessTTL  time.Duration
+	JWTRefreshTTL time.Duration
+
+	ModerationBannedWords     []string
+	ModerationMaxLinks        int
+	ModerationRateLimit       int
+	ModerationRateWindow      time.Duration
+	ModerationReportThreshold int
+
+	TrashRetention     time.Duration
+	TrashPurgeInterval time.Duration
 }
 
 func LoadConfig() (*Config, error) {
@@ -20,11 +42,108 @@ func LoadConfig() (*Config, error) {
 	// 	return nil, err
 	// }
 
+	cacheMemorySize, err := getEnvInt("CACHE_MEMORY_SIZE", 1000)
+	if err != nil {
+		return nil, err
+	}
+	redisDB, err := getEnvInt("REDIS_DB", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	accessTTL, err := getEnvDuration("JWT_ACCESS_TTL", 15*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	refreshTTL, err := getEnvDuration("JWT_REFRESH_TTL", 7*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	maxLinks, err := getEnvInt("MODERATION_MAX_LINKS", 2)
+	if err != nil {
+		return nil, err
+	}
+	rateLimit, err := getEnvInt("MODERATION_RATE_LIMIT", 5)
+	if err != nil {
+		return nil, err
+	}
+	rateWindow, err := getEnvDuration("MODERATION_RATE_WINDOW", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	reportThreshold, err := getEnvInt("MODERATION_REPORT_THRESHOLD", 3)
+	if err != nil {
+		return nil, err
+	}
+
+	trashRetention, err := getEnvDuration("TRASH_RETENTION", 30*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	trashPurgeInterval, err := getEnvDuration("TRASH_PURGE_INTERVAL", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Config{
 		DBUser:     os.Getenv("DB_USER"),
 		DBPassword: os.Getenv("DB_PASSWORD"),
 		DBHost:     os.Getenv("DB_HOST"),
 		DBPort:     os.Getenv("DB_PORT"),
 		DBName:     os.Getenv("DB_NAME"),
+
+		CacheBackend:    getEnv("CACHE_BACKEND", "redis"),
+		CacheMemorySize: cacheMemorySize,
+		RedisAddr:       getEnv("REDIS_ADDR", "localhost:6380"),
+		RedisPassword:   os.Getenv("REDIS_PASSWORD"),
+		RedisDB:         redisDB,
+
+		JWTSecret:     os.Getenv("JWT_SECRET"),
+		JWTAccessTTL:  accessTTL,
+		JWTRefreshTTL: refreshTTL,
+
+		ModerationBannedWords:     getEnvList("MODERATION_BANNED_WORDS"),
+		ModerationMaxLinks:        maxLinks,
+		ModerationRateLimit:       rateLimit,
+		ModerationRateWindow:      rateWindow,
+		ModerationReportThreshold: reportThreshold,
+
+		TrashRetention:     trashRetention,
+		TrashPurgeInterval: trashPurgeInterval,
 	}, nil
-}
\ No newline at end of file
+}
+
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func getEnvInt(key string, fallback int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	return strconv.Atoi(value)
+}
+
+// getEnvList splits a comma-separated variable, dropping empty items
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func getEnvDuration(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	return time.ParseDuration(value)
+}
@@ -0,0 +1,27 @@
+package constants
+
+// Entities recorded in the audit log. Posters and trailers are addressed by
+// their movie, so their entries carry the movie ID.
+const (
+	AuditEntityMovie   = "movie"
+	AuditEntityReview  = "review"
+	AuditEntityPoster  = "poster"
+	AuditEntityTrailer = "trailer"
+)
+
+var AllowedAuditEntities = map[string]bool{
+	AuditEntityMovie:   true,
+	AuditEntityReview:  true,
+	AuditEntityPoster:  true,
+	AuditEntityTrailer: true,
+}
+
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionRestore = "restore"
+	AuditActionPurge   = "purge" // Permanent deletion from the trash
+)
+
+const DefaultAuditPageSize = 50
@@ -0,0 +1,14 @@
+package constants
+
+const CreditRoleDirector = "director"
+
+// AllowedCreditRoles lists the roles a person can have in a movie's credits
+var AllowedCreditRoles = map[string]bool{
+	CreditRoleDirector: true,
+	"actor":            true,
+	"writer":           true,
+	"producer":         true,
+	"composer":         true,
+	"cinematographer":  true,
+	"editor":           true,
+}
@@ -0,0 +1,44 @@
+package constants
+
+// Orderings for a movie's review list
+const (
+	ReviewSortNewest  = "newest"
+	ReviewSortOldest  = "oldest"
+	ReviewSortHighest = "highest"
+	ReviewSortLowest  = "lowest"
+	ReviewSortHelpful = "helpful"
+)
+
+var AllowedReviewSorts = map[string]bool{
+	ReviewSortNewest:  true,
+	ReviewSortOldest:  true,
+	ReviewSortHighest: true,
+	ReviewSortLowest:  true,
+	ReviewSortHelpful: true,
+}
+
+// Replies nest at most MaxReplyDepth levels below the review. A deleted
+// review with replies keeps its place in the thread under the placeholder.
+const (
+	MaxReplyDepth            = 5
+	DeletedReviewPlaceholder = "[deleted]"
+)
+
+const (
+	DefaultReviewSort     = ReviewSortNewest
+	DefaultReviewPageSize = 20
+)
+
+// Moderation states of a review. Only approved reviews are listed
+// publicly and counted in a movie's rating.
+const (
+	ReviewStatusPending  = "pending"
+	ReviewStatusApproved = "approved"
+	ReviewStatusRejected = "rejected"
+)
+
+var AllowedReviewStatuses = map[string]bool{
+	ReviewStatusPending:  true,
+	ReviewStatusApproved: true,
+	ReviewStatusRejected: true,
+}
@@ -4,11 +4,51 @@ var AllowedSortFields = map[string]bool{
 	"title":        true,
 	"rating":       true,
 	"release_date": true,
+	"duration":     true,
+	"director":     true,
+	"created_at":   true,
+	"relevance":    true,
+
+	"average_user_rating": true,
+	"rating_count":        true,
+}
+
+// SortByRelevance orders full-text search results by ts_rank
+const SortByRelevance = "relevance"
+
+const (
+	SearchModeTitle    = "title"
+	SearchModeFullText = "fulltext"
+)
+
+// AllowedFacets lists the facet counts GET /movies can return
+var AllowedFacets = map[string]bool{
+	"genre":    true,
+	"language": true,
+	"decade":   true,
+	"rating":   true,
 }
 
+// Match modes for the genre, language and director filters
+const (
+	MatchContains = "contains"
+	MatchExact    = "exact"
+)
+
+// DefaultFuzzyThreshold is the minimum pg_trgm similarity for a fuzzy title match
+const DefaultFuzzyThreshold = 0.3
+
+const (
+	DefaultSortBy     = "title"
+	DefaultSearchMode = SearchModeTitle
+	DefaultMatch      = MatchContains
+	DefaultOrderBy    = "asc"
+	DefaultPage       = 1
+	DefaultPageSize   = 12
+)
+
+// Bounds of a review score
 const (
-	DefaultSortBy   = "title"
-	DefaultOrderBy  = "asc"
-	DefaultPage     = 1
-	DefaultPageSize = 12
+	MinReviewScore = 1
+	MaxReviewScore = 10
 )
@@ -0,0 +1,102 @@
+// Package diff compares two versions of a record field by field
+package diff
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Change holds the JSON values of one field before and after a change.
+// Old is left out for fields that did not exist yet, New for fields that
+// no longer exist.
+type Change struct {
+	Old json.RawMessage `json:"old,omitempty" swaggertype:"object"`
+	New json.RawMessage `json:"new,omitempty" swaggertype:"object"`
+}
+
+// Changes maps JSON field names to their change. It is stored as a jsonb column.
+type Changes map[string]Change
+
+// Compare returns the fields whose JSON encoding differs between before and
+// after. Either side may be nil, which lists every field of the other side.
+func Compare(before, after interface{}) (Changes, error) {
+	oldFields, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+	newFields, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := Changes{}
+	for name, value := range oldFields {
+		if !bytes.Equal(value, newFields[name]) {
+			changes[name] = Change{Old: value, New: newFields[name]}
+		}
+	}
+	for name, value := range newFields {
+		if _, ok := oldFields[name]; !ok {
+			changes[name] = Change{New: value}
+		}
+	}
+	return changes, nil
+}
+
+func (c Changes) Value() (driver.Value, error) {
+	if c == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (c *Changes) Scan(src interface{}) error {
+	var data []byte
+	switch value := src.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case []byte:
+		data = value
+	case string:
+		data = []byte(value)
+	default:
+		return fmt.Errorf("diff: cannot scan %T into Changes", src)
+	}
+	return json.Unmarshal(data, c)
+}
+
+// fields encodes v and splits the resulting JSON object into its fields.
+// Values are compacted so that equal fields compare byte for byte.
+func fields(v interface{}) (map[string]json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(data, []byte("null")) {
+		return nil, nil
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, errors.New("diff: only values that encode to JSON objects can be compared")
+	}
+	for name, value := range object {
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, value); err != nil {
+			return nil, err
+		}
+		object[name] = compact.Bytes()
+	}
+	return object, nil
+}
@@ -0,0 +1,111 @@
+package diff
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type record struct {
+	Title  string   `json:"title"`
+	Rating float64  `json:"rating"`
+	Genres []string `json:"genres"`
+	Hidden string   `json:"-"`
+}
+
+func TestCompareUpdate(t *testing.T) {
+	before := record{Title: "Heat", Rating: 8.1, Genres: []string{"Crime"}, Hidden: "a"}
+	after := &record{Title: "Heat", Rating: 8.3, Genres: []string{"Crime", "Drama"}, Hidden: "b"}
+
+	changes, err := Compare(before, after)
+	if err != nil {
+		t.Fatalf("Compare: %v", err)
+	}
+	want := Changes{
+		"rating": {Old: raw(`8.1`), New: raw(`8.3`)},
+		"genres": {Old: raw(`["Crime"]`), New: raw(`["Crime","Drama"]`)},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("Compare = %s, want %s", mustMarshal(t, changes), mustMarshal(t, want))
+	}
+}
+
+func TestCompareCreateAndDelete(t *testing.T) {
+	value := record{Title: "Heat", Rating: 8.3}
+
+	created, err := Compare(nil, value)
+	if err != nil {
+		t.Fatalf("Compare(nil, value): %v", err)
+	}
+	if len(created) != 3 || created["title"].Old != nil || string(created["title"].New) != `"Heat"` {
+		t.Errorf("Compare(nil, value) = %s", mustMarshal(t, created))
+	}
+
+	var missing *record
+	deleted, err := Compare(value, missing)
+	if err != nil {
+		t.Fatalf("Compare(value, nil pointer): %v", err)
+	}
+	if len(deleted) != 3 || deleted["rating"].New != nil || string(deleted["rating"].Old) != `8.3` {
+		t.Errorf("Compare(value, nil pointer) = %s", mustMarshal(t, deleted))
+	}
+}
+
+func TestCompareUnchanged(t *testing.T) {
+	// Equal values compare equal however they were indented
+	changes, err := Compare(
+		map[string]json.RawMessage{"genres": raw(`[ "Crime" ]`)},
+		map[string]json.RawMessage{"genres": raw(`["Crime"]`)},
+	)
+	if err != nil {
+		t.Fatalf("Compare: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("Compare = %s, want no changes", mustMarshal(t, changes))
+	}
+}
+
+func TestCompareRejectsNonObjects(t *testing.T) {
+	if _, err := Compare([]int{1}, []int{2}); err == nil {
+		t.Error("Compare of arrays should fail")
+	}
+}
+
+func TestChangesValueScan(t *testing.T) {
+	changes := Changes{"title": {Old: raw(`"Heat"`), New: raw(`"Heat 2"`)}}
+	value, err := changes.Value()
+	if err != nil {
+		t.Fatalf("Value: %v", err)
+	}
+
+	var scanned Changes
+	if err := scanned.Scan([]byte(value.(string))); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if !reflect.DeepEqual(scanned, changes) {
+		t.Errorf("Scan = %s, want %s", mustMarshal(t, scanned), mustMarshal(t, changes))
+	}
+
+	if value, err := Changes(nil).Value(); err != nil || value != "{}" {
+		t.Errorf("nil Value = %v, %v, want {}", value, err)
+	}
+	if err := scanned.Scan(nil); err != nil || scanned != nil {
+		t.Errorf("Scan(nil) = %v, %v, want nil", scanned, err)
+	}
+	if err := scanned.Scan(42); err == nil {
+		t.Error("Scan(int) should fail")
+	}
+}
+
+func raw(s string) json.RawMessage {
+	return json.RawMessage(s)
+}
+
+func mustMarshal(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	return string(data)
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Cladkoewka/movie-manager/internal/constants"
+	"github.com/Cladkoewka/movie-manager/internal/model/dto"
+	"github.com/Cladkoewka/movie-manager/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	auditService *service.AuditService
+}
+
+func NewAuditHandler(auditService *service.AuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+// GetAuditLog godoc
+// @Summary Get the audit log
+// @Description Get changes to movies, reviews, posters and trailers, newest first. Each entry holds the actor, the action and the changed fields with their old and new values.
+// @Tags admin
+// @Produce json
+// @Param entity query string false "Entity type (movie, review, poster or trailer)"
+// @Param entity_id query int false "Entity ID; posters and trailers use their movie's ID"
+// @Param actor query string false "Username of the actor"
+// @Param actor_id query int false "User ID of the actor"
+// @Param from query string false "Earliest change, as an RFC 3339 timestamp or YYYY-MM-DD"
+// @Param to query string false "Latest change, as an RFC 3339 timestamp or YYYY-MM-DD (inclusive)"
+// @Param page query int false "Page number for pagination"
+// @Param pageSize query int false "Number of items per page"
+// @Success 200 {object} dto.AuditLogResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /audit [get]
+func (h *AuditHandler) GetAuditLog(c *gin.Context) {
+	params, err := parseAuditQueryParams(c)
+	if err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	entries, err := h.auditService.GetEntries(params)
+	if err != nil {
+		var validationErr *service.ValidationError
+		if errors.As(err, &validationErr) {
+			respondValidationError(c, err)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit log"})
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+func parseAuditQueryParams(c *gin.Context) (dto.AuditQueryParams, error) {
+	params := dto.AuditQueryParams{
+		Entity: c.Query("entity"),
+		Actor:  c.Query("actor"),
+	}
+	var err error
+
+	if params.EntityID, err = queryInt64(c, "entity_id"); err != nil {
+		return params, err
+	}
+	if params.ActorID, err = queryInt64(c, "actor_id"); err != nil {
+		return params, err
+	}
+	if params.From, err = queryTime(c, "from", false); err != nil {
+		return params, err
+	}
+	if params.To, err = queryTime(c, "to", true); err != nil {
+		return params, err
+	}
+
+	if page, err := strconv.Atoi(c.Query("page")); err == nil && page > 0 {
+		params.Page = page
+	} else {
+		params.Page = constants.DefaultPage
+	}
+	if size, err := strconv.Atoi(c.Query("pageSize")); err == nil && size > 0 {
+		params.PageSize = size
+	} else {
+		params.PageSize = constants.DefaultAuditPageSize
+	}
+	return params, nil
+}
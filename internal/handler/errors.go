@@ -0,0 +1,19 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Cladkoewka/movie-manager/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// respondValidationError writes a 400 response naming the rejected field
+func respondValidationError(c *gin.Context, err error) {
+	var validationErr *service.ValidationError
+	if errors.As(err, &validationErr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error(), "field": validationErr.Field})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
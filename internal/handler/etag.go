@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Cladkoewka/movie-manager/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// errMissingIfMatch and errInvalidIfMatch are answered with 428 and 400
+var (
+	errMissingIfMatch = errors.New("If-Match header with the movie's ETag is required")
+	errInvalidIfMatch = errors.New("If-Match must be * or a list of ETags as returned by GET /movies/{id}")
+)
+
+// movieETag formats a movie version as an entity tag, e.g. "3"
+func movieETag(version int64) string {
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// parseIfMatch reads the movie versions a write may be based on (RFC 9110,
+// section 13.1.1): "*" for any version, or a comma-separated list of entity
+// tags. If-Match compares tags strongly, so weak tags never match; neither do
+// tags that are not a version of ours.
+func parseIfMatch(c *gin.Context) (service.VersionMatch, error) {
+	value := strings.TrimSpace(c.GetHeader("If-Match"))
+	if value == "" {
+		return service.VersionMatch{}, errMissingIfMatch
+	}
+	if value == "*" {
+		return service.VersionMatch{Any: true}, nil
+	}
+
+	var match service.VersionMatch
+	tags := 0
+	for {
+		value = strings.TrimLeft(value, " \t,")
+		if value == "" {
+			break
+		}
+		weak := strings.HasPrefix(value, "W/")
+		value = strings.TrimPrefix(value, "W/")
+		if !strings.HasPrefix(value, `"`) {
+			return service.VersionMatch{}, errInvalidIfMatch
+		}
+		end := strings.IndexByte(value[1:], '"') + 1
+		if end == 0 {
+			return service.VersionMatch{}, errInvalidIfMatch
+		}
+		tag := value[1:end]
+		value = strings.TrimLeft(value[end+1:], " \t")
+		if value != "" && value[0] != ',' {
+			return service.VersionMatch{}, errInvalidIfMatch
+		}
+
+		tags++
+		version, err := strconv.ParseInt(tag, 10, 64)
+		if !weak && err == nil && version > 0 && strconv.FormatInt(version, 10) == tag {
+			match.Versions = append(match.Versions, version)
+		}
+	}
+	if tags == 0 {
+		return service.VersionMatch{}, errInvalidIfMatch
+	}
+	return match, nil
+}
+
+// respondIfMatchError writes the response for an error from parseIfMatch
+func respondIfMatchError(c *gin.Context, err error) {
+	if errors.Is(err, errMissingIfMatch) {
+		c.JSON(http.StatusPreconditionRequired, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
+
+// respondVersionConflict writes a 412 naming the version the client has to
+// reload, which is also sent as the ETag
+func respondVersionConflict(c *gin.Context, conflict *service.VersionConflictError) {
+	c.Header("ETag", movieETag(conflict.CurrentVersion))
+	c.JSON(http.StatusPreconditionFailed, gin.H{
+		"error":           "Movie was changed since it was read",
+		"current_version": conflict.CurrentVersion,
+	})
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Cladkoewka/movie-manager/internal/model"
+	"github.com/Cladkoewka/movie-manager/internal/repository"
+	"github.com/Cladkoewka/movie-manager/internal/service"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// fakeMovieRepository holds one movie and checks versions like the real one
+type fakeMovieRepository struct {
+	repository.MovieRepository
+	movie model.Movie
+}
+
+func (r *fakeMovieRepository) GetMovieByID(id int64) (*model.Movie, error) {
+	if id != r.movie.ID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	movie := r.movie
+	return &movie, nil
+}
+
+func (r *fakeMovieRepository) UpdateMovie(movie model.Movie, authorID *int64, author string, audit repository.AuditFunc) (*model.Movie, error) {
+	if movie.Version != r.movie.Version {
+		return nil, repository.ErrVersionConflict
+	}
+	movie.Version++
+	r.movie = movie
+	return &movie, nil
+}
+
+func TestUpdateMovieIfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		ifMatch string
+		status  int
+		etag    string
+	}{
+		{"missing", "", http.StatusPreconditionRequired, ""},
+		{"current", `"3"`, http.StatusOK, `"4"`},
+		{"stale", `"2"`, http.StatusPreconditionFailed, `"3"`},
+		{"any", "*", http.StatusOK, `"4"`},
+		{"list with current", `"1", "3"`, http.StatusOK, `"4"`},
+		{"list without current", `"1","2"`, http.StatusPreconditionFailed, `"3"`},
+		{"weak", `W/"3"`, http.StatusPreconditionFailed, `"3"`},
+		{"weak and strong", `W/"3", "3"`, http.StatusOK, `"4"`},
+		{"not a version", `"03"`, http.StatusPreconditionFailed, `"3"`},
+		{"unquoted", "3", http.StatusBadRequest, ""},
+		{"unterminated", `"3`, http.StatusBadRequest, ""},
+		{"star in list", `*, "3"`, http.StatusBadRequest, ""},
+		{"only commas", ",", http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		repo := &fakeMovieRepository{movie: model.Movie{ID: 42, Title: "Heat", Version: 3}}
+		h := NewMovieHandler(service.NewMovieService(repo, nil, service.NewAuditService(nil)), nil)
+		r := gin.New()
+		r.PUT("/movies/:id", h.UpdateMovie)
+
+		body := `{"title": "Heat", "release_date": "1995-12-15T00:00:00Z"}`
+		req := httptest.NewRequest(http.MethodPut, "/movies/42", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if tt.ifMatch != "" {
+			req.Header.Set("If-Match", tt.ifMatch)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d (%s)", tt.name, w.Code, tt.status, w.Body)
+		}
+		if got := w.Header().Get("ETag"); got != tt.etag {
+			t.Errorf("%s: ETag = %q, want %q", tt.name, got, tt.etag)
+		}
+	}
+}
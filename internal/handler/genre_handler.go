@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Cladkoewka/movie-manager/internal/model"
+	"github.com/Cladkoewka/movie-manager/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type GenreHandler struct {
+	genreService *service.GenreService
+}
+
+func NewGenreHandler(genreService *service.GenreService) *GenreHandler {
+	return &GenreHandler{genreService: genreService}
+}
+
+// GetAllGenres godoc
+// @Summary Get all genres
+// @Description Get every genre ordered by name
+// @Tags genres
+// @Produce json
+// @Success 200 {array} model.Genre
+// @Failure 500 {object} map[string]string
+// @Router /genres [get]
+func (h *GenreHandler) GetAllGenres(c *gin.Context) {
+	genres, err := h.genreService.GetAllGenres()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch genres"})
+		return
+	}
+	c.JSON(http.StatusOK, genres)
+}
+
+// GetGenreByID godoc
+// @Summary Get a genre by ID
+// @Description Get a genre by its ID
+// @Tags genres
+// @Produce json
+// @Param id path int64 true "Genre ID"
+// @Success 200 {object} model.Genre
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /genres/{id} [get]
+func (h *GenreHandler) GetGenreByID(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid genre ID"})
+		return
+	}
+	genre, err := h.genreService.GetGenreByID(id)
+	if err != nil {
+		h.respondGenreError(c, err, "Failed to fetch genre")
+		return
+	}
+	c.JSON(http.StatusOK, genre)
+}
+
+// CreateGenre godoc
+// @Summary Create a genre
+// @Description Add a new genre
+// @Tags genres
+// @Accept json
+// @Produce json
+// @Param genre body model.Genre true "Genre payload"
+// @Success 201 {object} model.Genre
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /genres [post]
+func (h *GenreHandler) CreateGenre(c *gin.Context) {
+	var genre model.Genre
+	if err := c.ShouldBindJSON(&genre); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	created, err := h.genreService.CreateGenre(genre)
+	if err != nil {
+		h.respondGenreError(c, err, "Failed to create genre")
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+// UpdateGenre godoc
+// @Summary Rename a genre
+// @Description Update the name of an existing genre
+// @Tags genres
+// @Accept json
+// @Produce json
+// @Param id path int64 true "Genre ID"
+// @Param genre body model.Genre true "Genre payload"
+// @Success 200 {object} model.Genre
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /genres/{id} [put]
+func (h *GenreHandler) UpdateGenre(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid genre ID"})
+		return
+	}
+	var genre model.Genre
+	if err := c.ShouldBindJSON(&genre); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	genre.ID = id
+	updated, err := h.genreService.UpdateGenre(genre)
+	if err != nil {
+		h.respondGenreError(c, err, "Failed to update genre")
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteGenre godoc
+// @Summary Delete a genre
+// @Description Delete a genre and detach it from all movies
+// @Tags genres
+// @Param id path int64 true "Genre ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /genres/{id} [delete]
+func (h *GenreHandler) DeleteGenre(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid genre ID"})
+		return
+	}
+	if err := h.genreService.DeleteGenre(id); err != nil {
+		h.respondGenreError(c, err, "Failed to delete genre")
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (h *GenreHandler) respondGenreError(c *gin.Context, err error, message string) {
+	var validationErr *service.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		respondValidationError(c, err)
+	case errors.Is(err, service.ErrGenreNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Genre not found"})
+	case errors.Is(err, service.ErrGenreExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "Genre already exists"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
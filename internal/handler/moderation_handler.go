@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/Cladkoewka/movie-manager/internal/auth"
+	"github.com/Cladkoewka/movie-manager/internal/constants"
+	"github.com/Cladkoewka/movie-manager/internal/middleware"
+	"github.com/Cladkoewka/movie-manager/internal/model"
+	"github.com/Cladkoewka/movie-manager/internal/model/dto"
+	"github.com/Cladkoewka/movie-manager/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type ModerationHandler struct {
+	reviewService *service.ReviewService
+}
+
+func NewModerationHandler(reviewService *service.ReviewService) *ModerationHandler {
+	return &ModerationHandler{reviewService: reviewService}
+}
+
+// GetQueue godoc
+// @Summary Get the moderation queue
+// @Description Get reviews in a moderation state, oldest first
+// @Tags moderation
+// @Produce json
+// @Param status query string false "Review status: 'pending' (default), 'approved' or 'rejected'"
+// @Param page query int false "Page number for pagination"
+// @Param pageSize query int false "Number of reviews per page"
+// @Success 200 {object} dto.ModerationQueueResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /moderation/reviews [get]
+func (h *ModerationHandler) GetQueue(c *gin.Context) {
+	params := parseReviewQueryParams(c)
+	status := c.DefaultQuery("status", constants.ReviewStatusPending)
+	queue, err := h.reviewService.GetModerationQueue(status, params.Page, params.PageSize)
+	if err != nil {
+		respondReviewError(c, err, "Failed to fetch the moderation queue")
+		return
+	}
+	c.JSON(http.StatusOK, queue)
+}
+
+// ApproveReview godoc
+// @Summary Approve a review
+// @Description Publish a review and count its score in the movie rating
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param id path int true "Review ID"
+// @Param request body dto.ModerationRequest false "Optional note"
+// @Success 200 {object} model.Review
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /moderation/reviews/{id}/approve [post]
+func (h *ModerationHandler) ApproveReview(c *gin.Context) {
+	h.moderate(c, h.reviewService.ApproveReview)
+}
+
+// RejectReview godoc
+// @Summary Reject a review
+// @Description Hide a review from the public list and the movie rating
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param id path int true "Review ID"
+// @Param request body dto.ModerationRequest false "Optional reason shown to the author"
+// @Success 200 {object} model.Review
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /moderation/reviews/{id}/reject [post]
+func (h *ModerationHandler) RejectReview(c *gin.Context) {
+	h.moderate(c, h.reviewService.RejectReview)
+}
+
+type moderationAction func(moderator auth.Principal, id int64, reason string) (*model.Review, error)
+
+func (h *ModerationHandler) moderate(c *gin.Context, action moderationAction) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid review ID"})
+		return
+	}
+	// The body is optional
+	var request dto.ModerationRequest
+	if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	moderator, _ := middleware.CurrentUser(c)
+	review, err := action(moderator, id, request.Reason)
+	if err != nil {
+		respondReviewError(c, err, "Failed to moderate review")
+		return
+	}
+	c.JSON(http.StatusOK, review)
+}
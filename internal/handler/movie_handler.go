@@ -1,16 +1,21 @@
 package handler
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
 
 	"github.com/Cladkoewka/movie-manager/internal/constants"
+	"github.com/Cladkoewka/movie-manager/internal/middleware"
 	"github.com/Cladkoewka/movie-manager/internal/model"
-	"github.com/Cladkoewka/movie-manager/internal/model/dto"
 	"github.com/Cladkoewka/movie-manager/internal/service"
 	"github.com/gin-gonic/gin"
 )
 
+// mergePatchContentType is the media type of JSON Merge Patch bodies (RFC 7396)
+const mergePatchContentType = "application/merge-patch+json"
+
 type MovieHandler struct {
 	movieService *service.MovieService
 	moviePosterService *service.MoviePosterService
@@ -27,46 +32,48 @@ func NewMovieHandler(movieService *service.MovieService, moviePosterService *ser
 // @Accept json
 // @Produce json
 // @Param search query string false "Search term for movie title"
-// @Param genre query string false "Genre of the movie"
-// @Param language query string false "Language of the movie"
+// @Param search_mode query string false "Search mode: 'title' (substring of the title) or 'fulltext' (title, description and director)"
+// @Param fuzzy query bool false "Match titles by similarity to tolerate typos (used automatically when nothing matches exactly)"
+// @Param fuzzy_threshold query number false "Minimum title similarity for fuzzy matches (0-1, default 0.3)"
+// @Param genre query []string false "Genres of the movie, repeated or comma-separated; any may match" collectionFormat(multi)
+// @Param language query []string false "Languages of the movie, repeated or comma-separated; any may match" collectionFormat(multi)
+// @Param director query []string false "Directors of the movie, repeated or comma-separated; any may match" collectionFormat(multi)
+// @Param match query string false "How genre, language and director are matched: 'contains' (default) or 'exact' (case-insensitive)"
 // @Param rating query number false "Minimum rating of the movie (0-10)"
-// @Param sort_by query string false "Field to sort by (e.g. 'title', 'rating')"
-// @Param order query string false "Sort order: 'asc' or 'desc'"
+// @Param max_rating query number false "Maximum rating of the movie (0-10)"
+// @Param min_user_rating query number false "Minimum average review score (0-10)"
+// @Param max_user_rating query number false "Maximum average review score (0-10)"
+// @Param min_rating_count query int false "Minimum number of scored reviews"
+// @Param released_from query string false "Earliest release date, inclusive (YYYY-MM-DD)"
+// @Param released_to query string false "Latest release date, inclusive (YYYY-MM-DD)"
+// @Param year query int false "Release year"
+// @Param min_duration query int false "Minimum duration in minutes"
+// @Param max_duration query int false "Maximum duration in minutes"
+// @Param facets query string false "Comma-separated facets to count: genre, language, decade, rating"
+// @Param sort query string false "Comma-separated sort fields, '-' prefix for descending (e.g. '-rating,release_date,title'). Fields: title, rating, release_date, duration, director, created_at, average_user_rating, rating_count, relevance. id is always the final tiebreaker"
+// @Param sort_by query string false "Single field to sort by when sort is not given (e.g. 'title', 'rating', 'relevance')"
+// @Param order query string false "Sort order for sort_by: 'asc' or 'desc'"
 // @Param page query int false "Page number for pagination"
 // @Param pageSize query int false "Number of items per page"
+// @Param cursor query string false "Opaque cursor from next_cursor/prev_cursor for keyset pagination; replaces page"
 // @Success 200 {object} dto.MoviesResponse
+// @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /movies [get]
 func (h *MovieHandler) GetAllMovies(c *gin.Context) {
-	var params dto.MovieQueryParams
-	params.Search = c.DefaultQuery("search", "")
-	params.Genre = c.DefaultQuery("genre", "")
-	params.Language = c.DefaultQuery("language", "")
-
-	if ratingStr := c.Query("rating"); ratingStr != "" {
-		rating, err := strconv.ParseFloat(ratingStr, 64)
-		if err == nil {
-			params.Rating = &rating
-		}
-	}
-
-	params.SortBy = c.DefaultQuery("sort_by", constants.DefaultSortBy)
-	params.OrderBy = c.DefaultQuery("order", constants.DefaultOrderBy)
-
-	if page, err := strconv.Atoi(c.DefaultQuery("page", strconv.Itoa(constants.DefaultPage))); err == nil && page > 0 {
-		params.Page = page
-	} else {
-		params.Page = constants.DefaultPage
-	}
-
-	if size, err := strconv.Atoi(c.DefaultQuery("pageSize", strconv.Itoa(constants.DefaultPageSize))); err == nil && size > 0 {
-		params.PageSize = size
-	} else {
-		params.PageSize = constants.DefaultPageSize
+	params, err := parseMovieQueryParams(c)
+	if err != nil {
+		respondValidationError(c, err)
+		return
 	}
 
 	moviesResponse, err := h.movieService.GetAllMovies(params)
 	if err != nil {
+		var validationErr *service.ValidationError
+		if errors.As(err, &validationErr) {
+			respondValidationError(c, err)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch movies"})
 		return 
 	}
@@ -75,12 +82,13 @@ func (h *MovieHandler) GetAllMovies(c *gin.Context) {
 
 // GetMovieByID godoc
 // @Summary Get a movie by ID
-// @Description Get a movie details by movie ID
+// @Description Get a movie details by movie ID. The ETag header carries the movie's version for If-Match on PUT.
 // @Tags movies
 // @Accept json
 // @Produce json
 // @Param id path int64 true "Movie ID"
 // @Success 200 {object} model.Movie
+// @Header 200 {string} ETag "Movie version"
 // @Failure 400 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
 // @Router /movies/{id} [get]
@@ -95,19 +103,23 @@ func (h *MovieHandler) GetMovieByID(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
 		return
 	}
+	c.Header("ETag", movieETag(movie.Version))
 	c.JSON(http.StatusOK, movie)
 }
 
 // CreateMovie godoc
 // @Summary Create a new movie
-// @Description Add a new movie to the database
+// @Description Add a new movie to the database. Genres are given by id or by name; unknown names are created
 // @Tags movies
 // @Accept json
 // @Produce json
 // @Param movie body model.Movie true "Movie details"
 // @Success 201 {object} model.Movie
 // @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
 // @Router /movies [post]
 func (h *MovieHandler) CreateMovie(c *gin.Context) {
 	var movie model.Movie
@@ -115,25 +127,40 @@ func (h *MovieHandler) CreateMovie(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
 		return
 	}
-	newMovie, err := h.movieService.CreateMovie(movie)
+	actor, _ := middleware.CurrentUser(c)
+	newMovie, err := h.movieService.CreateMovie(actor, movie)
 	if err != nil {
+		var validationErr *service.ValidationError
+		if errors.As(err, &validationErr) {
+			respondValidationError(c, err)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create movie"})
 		return
 	}
+	c.Header("ETag", movieETag(newMovie.Version))
 	c.JSON(http.StatusCreated, newMovie)
 }
 
 // UpdateMovie godoc
 // @Summary Update an existing movie
-// @Description Update the details of an existing movie
+// @Description Update the details of an existing movie. The genres list replaces the current one. If-Match must carry the ETag the changes are based on; if the movie has changed since, nothing is saved and 412 returns the current version.
 // @Tags movies
 // @Accept json
 // @Produce json
 // @Param id path int64 true "Movie ID"
+// @Param If-Match header string true "ETag from GET /movies/{id}, a comma-separated list of ETags or *"
 // @Param movie body model.Movie true "Movie details"
 // @Success 200 {object} model.Movie
+// @Header 200 {string} ETag "New movie version"
 // @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 412 {object} map[string]interface{}
+// @Failure 428 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
 // @Router /movies/{id} [put]
 func (h *MovieHandler) UpdateMovie(c *gin.Context) {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
@@ -141,30 +168,104 @@ func (h *MovieHandler) UpdateMovie(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID"})
 		return
 	}
+	match, err := parseIfMatch(c)
+	if err != nil {
+		respondIfMatchError(c, err)
+		return
+	}
+	version, err := h.movieService.MatchVersion(id, match)
+	if err != nil {
+		respondMovieError(c, err, "Failed to update movie")
+		return
+	}
 	var movie model.Movie
 	if err := c.ShouldBindJSON(&movie); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
 		return
 	}
 	movie.ID = id
-	updatedMovie, err := h.movieService.UpdateMovie(movie)
+	movie.Version = version
+	actor, _ := middleware.CurrentUser(c)
+	updatedMovie, err := h.movieService.UpdateMovie(actor, movie)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update movie"})
+		respondMovieError(c, err, "Failed to update movie")
 		return
 	}
+	c.Header("ETag", movieETag(updatedMovie.Version))
 	c.JSON(http.StatusOK, updatedMovie)
 }
 
+// PatchMovie godoc
+// @Summary Partially update a movie
+// @Description Apply a JSON Merge Patch (RFC 7396): only the fields in the body change, null clears a field and genres are replaced as a whole list. The result is checked like a new movie. If-Match works as for PUT.
+// @Tags movies
+// @Accept application/merge-patch+json
+// @Accept json
+// @Produce json
+// @Param id path int64 true "Movie ID"
+// @Param If-Match header string true "ETag from GET /movies/{id}, a comma-separated list of ETags or *"
+// @Param patch body model.Movie true "Fields to change"
+// @Success 200 {object} model.Movie
+// @Header 200 {string} ETag "New movie version"
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 412 {object} map[string]interface{}
+// @Failure 415 {object} map[string]interface{}
+// @Failure 428 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /movies/{id} [patch]
+func (h *MovieHandler) PatchMovie(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID"})
+		return
+	}
+	if contentType := c.ContentType(); contentType != mergePatchContentType && contentType != gin.MIMEJSON {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Content-Type must be " + mergePatchContentType})
+		return
+	}
+	match, err := parseIfMatch(c)
+	if err != nil {
+		respondIfMatchError(c, err)
+		return
+	}
+	version, err := h.movieService.MatchVersion(id, match)
+	if err != nil {
+		respondMovieError(c, err, "Failed to update movie")
+		return
+	}
+	patch, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	actor, _ := middleware.CurrentUser(c)
+	patchedMovie, err := h.movieService.PatchMovie(actor, id, version, patch)
+	if err != nil {
+		respondMovieError(c, err, "Failed to update movie")
+		return
+	}
+	c.Header("ETag", movieETag(patchedMovie.Version))
+	c.JSON(http.StatusOK, patchedMovie)
+}
+
 // DeleteMovie godoc
 // @Summary Delete a movie by ID
-// @Description Remove a movie from the database by its ID
+// @Description Move a movie, its reviews and its posters to the trash
 // @Tags movies
 // @Accept json
 // @Produce json
 // @Param id path int64 true "Movie ID"
 // @Success 204 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
 // @Router /movies/{id} [delete]
 func (h *MovieHandler) DeleteMovie(c *gin.Context) {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
@@ -172,7 +273,12 @@ func (h *MovieHandler) DeleteMovie(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID"})
 		return
 	}
-	err = h.movieService.DeleteMovie(id)
+	actor, _ := middleware.CurrentUser(c)
+	err = h.movieService.DeleteMovie(actor, id)
+	if errors.Is(err, service.ErrMovieNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete movie"})
 		return
@@ -180,6 +286,213 @@ func (h *MovieHandler) DeleteMovie(c *gin.Context) {
 	c.JSON(http.StatusNoContent, nil)
 }
 
+// GetTrash godoc
+// @Summary Get deleted movies
+// @Description Get movies in the trash, most recently deleted first. They are purged after the retention period.
+// @Tags movies
+// @Produce json
+// @Param page query int false "Page number for pagination"
+// @Param pageSize query int false "Number of items per page"
+// @Success 200 {object} dto.MoviesResponse
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /movies/trash [get]
+func (h *MovieHandler) GetTrash(c *gin.Context) {
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil || page < 1 {
+		page = constants.DefaultPage
+	}
+	pageSize, err := strconv.Atoi(c.Query("pageSize"))
+	if err != nil || pageSize < 1 {
+		pageSize = constants.DefaultPageSize
+	}
+	trash, err := h.movieService.GetTrash(page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deleted movies"})
+		return
+	}
+	c.JSON(http.StatusOK, trash)
+}
+
+// RestoreMovie godoc
+// @Summary Restore a deleted movie
+// @Description Take a movie out of the trash together with the reviews and posters deleted with it
+// @Tags movies
+// @Produce json
+// @Param id path int64 true "Movie ID"
+// @Success 200 {object} model.Movie
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /movies/{id}/restore [post]
+func (h *MovieHandler) RestoreMovie(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID"})
+		return
+	}
+	actor, _ := middleware.CurrentUser(c)
+	movie, err := h.movieService.RestoreMovie(actor, id)
+	if errors.Is(err, service.ErrMovieNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found in trash"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore movie"})
+		return
+	}
+	c.JSON(http.StatusOK, movie)
+}
+
+// GetRevisions godoc
+// @Summary Get the revision history of a movie
+// @Description Get the saved versions of a movie, newest first. Every create, update and restore adds one.
+// @Tags movies
+// @Produce json
+// @Param id path int64 true "Movie ID"
+// @Param page query int false "Page number for pagination"
+// @Param pageSize query int false "Number of items per page"
+// @Success 200 {object} dto.MovieRevisionsResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /movies/{id}/revisions [get]
+func (h *MovieHandler) GetRevisions(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID"})
+		return
+	}
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil || page < 1 {
+		page = constants.DefaultPage
+	}
+	pageSize, err := strconv.Atoi(c.Query("pageSize"))
+	if err != nil || pageSize < 1 {
+		pageSize = constants.DefaultPageSize
+	}
+	revisions, err := h.movieService.GetRevisions(id, page, pageSize)
+	if err != nil {
+		respondMovieError(c, err, "Failed to fetch revisions")
+		return
+	}
+	c.JSON(http.StatusOK, revisions)
+}
+
+// GetRevisionDiff godoc
+// @Summary Get the changes made by a movie revision
+// @Description Compare a revision with the one before it, field by field. The first revision lists every field.
+// @Tags movies
+// @Produce json
+// @Param id path int64 true "Movie ID"
+// @Param rev path int true "Revision number"
+// @Success 200 {object} dto.MovieRevisionDiff
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /movies/{id}/revisions/{rev}/diff [get]
+func (h *MovieHandler) GetRevisionDiff(c *gin.Context) {
+	id, rev, ok := parseRevisionParams(c)
+	if !ok {
+		return
+	}
+	revisionDiff, err := h.movieService.GetRevisionDiff(id, rev)
+	if err != nil {
+		respondMovieError(c, err, "Failed to compare revisions")
+		return
+	}
+	c.JSON(http.StatusOK, revisionDiff)
+}
+
+// RestoreRevision godoc
+// @Summary Roll a movie back to a revision
+// @Description Restore the fields and genres a movie had in the given revision. Genres deleted since then are looked up by name again. The result is saved as a new revision. If-Match works as for PUT.
+// @Tags movies
+// @Produce json
+// @Param id path int64 true "Movie ID"
+// @Param rev path int true "Revision number"
+// @Param If-Match header string true "ETag from GET /movies/{id}, a comma-separated list of ETags or *"
+// @Success 200 {object} model.Movie
+// @Header 200 {string} ETag "New movie version"
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 412 {object} map[string]interface{}
+// @Failure 428 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /movies/{id}/revisions/{rev}/restore [post]
+func (h *MovieHandler) RestoreRevision(c *gin.Context) {
+	id, rev, ok := parseRevisionParams(c)
+	if !ok {
+		return
+	}
+	match, err := parseIfMatch(c)
+	if err != nil {
+		respondIfMatchError(c, err)
+		return
+	}
+	version, err := h.movieService.MatchVersion(id, match)
+	if err != nil {
+		respondMovieError(c, err, "Failed to restore revision")
+		return
+	}
+	actor, _ := middleware.CurrentUser(c)
+	movie, err := h.movieService.RestoreRevision(actor, id, version, rev)
+	if err != nil {
+		respondMovieError(c, err, "Failed to restore revision")
+		return
+	}
+	c.Header("ETag", movieETag(movie.Version))
+	c.JSON(http.StatusOK, movie)
+}
+
+// parseRevisionParams reads the movie ID and revision number from the path,
+// answering 400 itself when either is malformed
+func parseRevisionParams(c *gin.Context) (int64, int, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID"})
+		return 0, 0, false
+	}
+	rev, err := strconv.Atoi(c.Param("rev"))
+	if err != nil || rev < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision"})
+		return 0, 0, false
+	}
+	return id, rev, true
+}
+
+// respondMovieError maps the errors of movie updates and revision lookups to responses
+func respondMovieError(c *gin.Context, err error, message string) {
+	var validationErr *service.ValidationError
+	var conflict *service.VersionConflictError
+	switch {
+	case errors.As(err, &validationErr):
+		respondValidationError(c, err)
+	case errors.As(err, &conflict):
+		respondVersionConflict(c, conflict)
+	case errors.Is(err, service.ErrMovieNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+	case errors.Is(err, service.ErrRevisionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Revision not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
 // UploadPoster godoc
 // @Summary Upload a movie poster
 // @Description Upload a poster for a movie by its ID
@@ -190,7 +503,11 @@ func (h *MovieHandler) DeleteMovie(c *gin.Context) {
 // @Param poster formData file true "Movie Poster"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
 // @Router /movies/{id}/poster [post]
 func (h *MovieHandler) UploadPoster(c *gin.Context) {
 	movieID, err := strconv.ParseInt(c.Param("id"), 10, 64)
@@ -214,7 +531,12 @@ func (h *MovieHandler) UploadPoster(c *gin.Context) {
 
 	// Сохраняем постер, передавая в сервис сам файл
 	mimeType := file.Header.Get("Content-Type")
-	err = h.moviePosterService.SavePoster(movieID, fileData, mimeType)
+	actor, _ := middleware.CurrentUser(c)
+	err = h.moviePosterService.SavePoster(actor, movieID, fileData, mimeType)
+	if errors.Is(err, service.ErrMovieNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save poster", "details": err.Error()})
 		return
@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Cladkoewka/movie-manager/internal/constants"
+	"github.com/Cladkoewka/movie-manager/internal/model/dto"
+	"github.com/Cladkoewka/movie-manager/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+const queryDateLayout = "2006-01-02"
+
+// parseMovieQueryParams reads the GET /movies query string. Values that are
+// present but malformed are reported as validation errors.
+func parseMovieQueryParams(c *gin.Context) (dto.MovieQueryParams, error) {
+	var params dto.MovieQueryParams
+	var err error
+
+	params.Search = c.DefaultQuery("search", "")
+	params.SearchMode = c.DefaultQuery("search_mode", constants.DefaultSearchMode)
+	if params.Fuzzy, err = queryBool(c, "fuzzy"); err != nil {
+		return params, err
+	}
+	if threshold, err := queryFloat(c, "fuzzy_threshold"); err != nil {
+		return params, err
+	} else if threshold != nil {
+		params.FuzzyThreshold = *threshold
+	}
+	params.Genres = queryList(c, "genre")
+	params.Languages = queryList(c, "language")
+	params.Directors = queryList(c, "director")
+	params.Match = c.DefaultQuery("match", constants.DefaultMatch)
+
+	if params.Rating, err = queryFloat(c, "rating"); err != nil {
+		return params, err
+	}
+	if params.MaxRating, err = queryFloat(c, "max_rating"); err != nil {
+		return params, err
+	}
+	if params.MinUserRating, err = queryFloat(c, "min_user_rating"); err != nil {
+		return params, err
+	}
+	if params.MaxUserRating, err = queryFloat(c, "max_user_rating"); err != nil {
+		return params, err
+	}
+	if params.MinRatingCount, err = queryInt(c, "min_rating_count"); err != nil {
+		return params, err
+	}
+	if params.ReleasedFrom, err = queryDate(c, "released_from"); err != nil {
+		return params, err
+	}
+	if params.ReleasedTo, err = queryDate(c, "released_to"); err != nil {
+		return params, err
+	}
+	if params.Year, err = queryInt(c, "year"); err != nil {
+		return params, err
+	}
+	if params.MinDuration, err = queryInt(c, "min_duration"); err != nil {
+		return params, err
+	}
+	if params.MaxDuration, err = queryInt(c, "max_duration"); err != nil {
+		return params, err
+	}
+
+	params.Facets = queryList(c, "facets")
+
+	// sort=-rating,title takes precedence over the older sort_by/order pair
+	if sort := queryList(c, "sort"); len(sort) > 0 {
+		params.Sort = parseSortFields(sort)
+	} else {
+		params.Sort = []dto.SortField{{
+			Field: c.DefaultQuery("sort_by", constants.DefaultSortBy),
+			Desc:  c.DefaultQuery("order", constants.DefaultOrderBy) == "desc",
+		}}
+	}
+
+	if page, err := strconv.Atoi(c.DefaultQuery("page", strconv.Itoa(constants.DefaultPage))); err == nil && page > 0 {
+		params.Page = page
+	} else {
+		params.Page = constants.DefaultPage
+	}
+
+	if size, err := strconv.Atoi(c.DefaultQuery("pageSize", strconv.Itoa(constants.DefaultPageSize))); err == nil && size > 0 {
+		params.PageSize = size
+	} else {
+		params.PageSize = constants.DefaultPageSize
+	}
+
+	params.Cursor = c.Query("cursor")
+
+	return params, nil
+}
+
+// parseSortFields reads fields like "-rating" (descending) or "title" (ascending)
+func parseSortFields(values []string) []dto.SortField {
+	fields := make([]dto.SortField, len(values))
+	for i, value := range values {
+		switch {
+		case strings.HasPrefix(value, "-"):
+			fields[i] = dto.SortField{Field: value[1:], Desc: true}
+		case strings.HasPrefix(value, "+"):
+			fields[i] = dto.SortField{Field: value[1:]}
+		default:
+			fields[i] = dto.SortField{Field: value}
+		}
+	}
+	return fields
+}
+
+// queryList collects a parameter that may be repeated and may hold comma-separated values
+func queryList(c *gin.Context, name string) []string {
+	var values []string
+	for _, raw := range c.QueryArray(name) {
+		for _, value := range strings.Split(raw, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+func queryBool(c *gin.Context, name string) (bool, error) {
+	value := c.Query(name)
+	if value == "" {
+		return false, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, service.NewValidationError(name, "must be true or false")
+	}
+	return parsed, nil
+}
+
+func queryFloat(c *gin.Context, name string) (*float64, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, service.NewValidationError(name, "must be a number")
+	}
+	return &parsed, nil
+}
+
+func queryInt(c *gin.Context, name string) (*int, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, service.NewValidationError(name, "must be an integer")
+	}
+	return &parsed, nil
+}
+
+func queryDate(c *gin.Context, name string) (*time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(queryDateLayout, value)
+	if err != nil {
+		return nil, service.NewValidationError(name, "must be a date in YYYY-MM-DD format")
+	}
+	return &parsed, nil
+}
+
+func queryInt64(c *gin.Context, name string) (*int64, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, service.NewValidationError(name, "must be an integer")
+	}
+	return &parsed, nil
+}
+
+// queryTime accepts an RFC 3339 timestamp or a plain date. With endOfDay set,
+// a plain date stands for its last instant, so that it works as an inclusive
+// upper bound.
+func queryTime(c *gin.Context, name string, endOfDay bool) (*time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return &parsed, nil
+	}
+	parsed, err := time.Parse(queryDateLayout, value)
+	if err != nil {
+		return nil, service.NewValidationError(name, "must be an RFC 3339 timestamp or a date in YYYY-MM-DD format")
+	}
+	if endOfDay {
+		parsed = parsed.Add(24*time.Hour - time.Nanosecond)
+	}
+	return &parsed, nil
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"strconv"
+	"github.com/Cladkoewka/movie-manager/internal/middleware"
 	"github.com/Cladkoewka/movie-manager/internal/service"
 )
 
@@ -25,7 +26,10 @@ func NewMovieTrailerHandler(movieTrailerService *service.MovieTrailerService) *M
 // @Param trailer formData file true "Trailer file"
 // @Success 200 {object} map[string]string
 // @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Failure 500 {object} map[string]string
+// @Security BearerAuth
 // @Router /movies/{id}/trailer [post]
 func (h *MovieTrailerHandler) UploadTrailer(c *gin.Context) {
 	movieID, err := strconv.ParseInt(c.Param("id"), 10, 64)
@@ -40,7 +44,8 @@ func (h *MovieTrailerHandler) UploadTrailer(c *gin.Context) {
 		return
 	}
 
-	err = h.movieTrailerService.UploadTrailer(movieID, file)
+	actor, _ := middleware.CurrentUser(c)
+	err = h.movieTrailerService.UploadTrailer(actor, movieID, file)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload trailer"})
 	}
@@ -58,7 +63,10 @@ func (h *MovieTrailerHandler) UploadTrailer(c *gin.Context) {
 // @Param url query string true "Trailer URL" 
 // @Success 200 {object} map[string]string
 // @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Failure 500 {object} map[string]string
+// @Security BearerAuth
 // @Router /movies/{id}/trailer [put]
 func (h *MovieTrailerHandler) SetTrailerUrl(c *gin.Context) {
 	movieID, err := strconv.ParseInt(c.Param("id"), 10, 64)
@@ -73,7 +81,8 @@ func (h *MovieTrailerHandler) SetTrailerUrl(c *gin.Context) {
 		return
 	}
 
-	err = h.movieTrailerService.SetTrailerURL(movieID, url)
+	actor, _ := middleware.CurrentUser(c)
+	err = h.movieTrailerService.SetTrailerURL(actor, movieID, url)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set trailer URL"})
 		return
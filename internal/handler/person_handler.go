@@ -0,0 +1,285 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Cladkoewka/movie-manager/internal/model"
+	"github.com/Cladkoewka/movie-manager/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type PersonHandler struct {
+	personService *service.PersonService
+}
+
+func NewPersonHandler(personService *service.PersonService) *PersonHandler {
+	return &PersonHandler{personService: personService}
+}
+
+// GetAllPeople godoc
+// @Summary Get all people
+// @Description Get directors, actors and crew ordered by name
+// @Tags people
+// @Produce json
+// @Param search query string false "Part of the person's name"
+// @Success 200 {array} model.Person
+// @Failure 500 {object} map[string]string
+// @Router /people [get]
+func (h *PersonHandler) GetAllPeople(c *gin.Context) {
+	people, err := h.personService.GetAllPeople(c.Query("search"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch people"})
+		return
+	}
+	c.JSON(http.StatusOK, people)
+}
+
+// GetPersonByID godoc
+// @Summary Get a person by ID
+// @Description Get a person by their ID
+// @Tags people
+// @Produce json
+// @Param id path int64 true "Person ID"
+// @Success 200 {object} model.Person
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /people/{id} [get]
+func (h *PersonHandler) GetPersonByID(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid person ID"})
+		return
+	}
+	person, err := h.personService.GetPersonByID(id)
+	if err != nil {
+		respondPersonError(c, err, "Failed to fetch person")
+		return
+	}
+	c.JSON(http.StatusOK, person)
+}
+
+// CreatePerson godoc
+// @Summary Create a person
+// @Description Add a director, actor or crew member
+// @Tags people
+// @Accept json
+// @Produce json
+// @Param person body model.Person true "Person payload"
+// @Success 201 {object} model.Person
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /people [post]
+func (h *PersonHandler) CreatePerson(c *gin.Context) {
+	var person model.Person
+	if err := c.ShouldBindJSON(&person); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	created, err := h.personService.CreatePerson(person)
+	if err != nil {
+		respondPersonError(c, err, "Failed to create person")
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+// UpdatePerson godoc
+// @Summary Update a person
+// @Description Update the details of an existing person
+// @Tags people
+// @Accept json
+// @Produce json
+// @Param id path int64 true "Person ID"
+// @Param person body model.Person true "Person payload"
+// @Success 200 {object} model.Person
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /people/{id} [put]
+func (h *PersonHandler) UpdatePerson(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid person ID"})
+		return
+	}
+	var person model.Person
+	if err := c.ShouldBindJSON(&person); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	person.ID = id
+	updated, err := h.personService.UpdatePerson(person)
+	if err != nil {
+		respondPersonError(c, err, "Failed to update person")
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeletePerson godoc
+// @Summary Delete a person
+// @Description Delete a person together with all of their credits
+// @Tags people
+// @Param id path int64 true "Person ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /people/{id} [delete]
+func (h *PersonHandler) DeletePerson(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid person ID"})
+		return
+	}
+	if err := h.personService.DeletePerson(id); err != nil {
+		respondPersonError(c, err, "Failed to delete person")
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GetFilmography godoc
+// @Summary Get a person's filmography
+// @Description Get every movie credit of a person, newest release first
+// @Tags people
+// @Produce json
+// @Param id path int64 true "Person ID"
+// @Success 200 {object} dto.Filmography
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /people/{id}/filmography [get]
+func (h *PersonHandler) GetFilmography(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid person ID"})
+		return
+	}
+	filmography, err := h.personService.GetFilmography(id)
+	if err != nil {
+		respondPersonError(c, err, "Failed to fetch filmography")
+		return
+	}
+	c.JSON(http.StatusOK, filmography)
+}
+
+// GetMovieCredits godoc
+// @Summary Get movie credits
+// @Description Get the cast and crew of a movie in billing order
+// @Tags people
+// @Produce json
+// @Param id path int64 true "Movie ID"
+// @Success 200 {array} model.MovieCredit
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /movies/{id}/credits [get]
+func (h *PersonHandler) GetMovieCredits(c *gin.Context) {
+	movieID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID"})
+		return
+	}
+	credits, err := h.personService.GetMovieCredits(movieID)
+	if err != nil {
+		respondPersonError(c, err, "Failed to fetch credits")
+		return
+	}
+	c.JSON(http.StatusOK, credits)
+}
+
+// AddMovieCredit godoc
+// @Summary Add a movie credit
+// @Description Credit a person in a movie with a role (director, actor, writer, producer, composer, cinematographer, editor)
+// @Tags people
+// @Accept json
+// @Produce json
+// @Param id path int64 true "Movie ID"
+// @Param credit body model.MovieCredit true "Credit payload"
+// @Success 201 {object} model.MovieCredit
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /movies/{id}/credits [post]
+func (h *PersonHandler) AddMovieCredit(c *gin.Context) {
+	movieID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID"})
+		return
+	}
+	var credit model.MovieCredit
+	if err := c.ShouldBindJSON(&credit); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	credit.MovieID = movieID
+	created, err := h.personService.AddMovieCredit(credit)
+	if err != nil {
+		respondPersonError(c, err, "Failed to add credit")
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+// DeleteMovieCredit godoc
+// @Summary Delete a movie credit
+// @Description Remove a credit from a movie
+// @Tags people
+// @Param id path int64 true "Movie ID"
+// @Param credit_id path int64 true "Credit ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /movies/{id}/credits/{credit_id} [delete]
+func (h *PersonHandler) DeleteMovieCredit(c *gin.Context) {
+	movieID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID"})
+		return
+	}
+	creditID, err := strconv.ParseInt(c.Param("credit_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid credit ID"})
+		return
+	}
+	if err := h.personService.DeleteMovieCredit(movieID, creditID); err != nil {
+		respondPersonError(c, err, "Failed to delete credit")
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func respondPersonError(c *gin.Context, err error, message string) {
+	var validationErr *service.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		respondValidationError(c, err)
+	case errors.Is(err, service.ErrPersonNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Person not found"})
+	case errors.Is(err, service.ErrMovieNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+	case errors.Is(err, service.ErrCreditNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Credit not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
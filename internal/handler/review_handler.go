@@ -1,10 +1,15 @@
 package handler
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
 
+	"github.com/Cladkoewka/movie-manager/internal/constants"
+	"github.com/Cladkoewka/movie-manager/internal/middleware"
 	"github.com/Cladkoewka/movie-manager/internal/model"
+	"github.com/Cladkoewka/movie-manager/internal/model/dto"
 	"github.com/Cladkoewka/movie-manager/internal/service"
 	"github.com/gin-gonic/gin"
 )
@@ -18,12 +23,16 @@ func NewReviewHandler(service *service.ReviewService) *ReviewHandler {
 }
 
 // GetReviewsByMovieID godoc
-// @Summary Get all reviews for a movie
-// @Description Get all reviews by Movie ID
+// @Summary Get reviews for a movie
+// @Description Get a page of a movie's reviews with the total count and a score histogram
 // @Tags reviews
 // @Param movie_id path int true "Movie ID"
+// @Param sort query string false "Order: 'newest' (default), 'oldest', 'highest' or 'lowest' score, or 'helpful' (most net helpful votes)"
+// @Param page query int false "Page number for pagination"
+// @Param pageSize query int false "Number of reviews per page"
+// @Param cursor query string false "Opaque cursor from next_cursor/prev_cursor for keyset pagination; replaces page"
 // @Produce json
-// @Success 200 {array} model.Review
+// @Success 200 {object} dto.ReviewsResponse
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /reviews/movie/{movie_id} [get]
@@ -33,47 +42,174 @@ func (h *ReviewHandler) GetReviewsByMovieID(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID"})
 		return
 	}
-	reviews, err := h.reviewService.GetAllByMovieID(movieID)
+	response, err := h.reviewService.GetByMovieID(movieID, parseReviewQueryParams(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reviews"})
+		respondReviewError(c, err, "Failed to fetch reviews")
 		return
 	}
-	c.JSON(http.StatusOK, reviews)
+	c.JSON(http.StatusOK, response)
+}
+
+func parseReviewQueryParams(c *gin.Context) dto.ReviewQueryParams {
+	params := dto.ReviewQueryParams{
+		Sort:     c.DefaultQuery("sort", constants.DefaultReviewSort),
+		Page:     constants.DefaultPage,
+		PageSize: constants.DefaultReviewPageSize,
+		Cursor:   c.Query("cursor"),
+	}
+	if page, err := strconv.Atoi(c.Query("page")); err == nil && page > 0 {
+		params.Page = page
+	}
+	if size, err := strconv.Atoi(c.Query("pageSize")); err == nil && size > 0 {
+		params.PageSize = size
+	}
+	return params
 }
 
 // CreateReview godoc
 // @Summary Create a review
-// @Description Create a new review for a movie
+// @Description Create a new review for a movie, authored by the caller
 // @Tags reviews
 // @Accept json
 // @Produce json
-// @Param review body model.Review true "Review payload"
+// @Param review body dto.ReviewRequest true "Review payload"
 // @Success 201 {object} model.Review
 // @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 422 {object} map[string]string
 // @Failure 500 {object} map[string]string
+// @Security BearerAuth
 // @Router /reviews [post]
 func (h *ReviewHandler) CreateReview(c *gin.Context) {
-	var review model.Review
-	if err := c.ShouldBindJSON(&review); err != nil {
+	var request dto.ReviewRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
 		return
 	}
-	created, err := h.reviewService.CreateReview(review)
+	author, _ := middleware.CurrentUser(c)
+	created, err := h.reviewService.CreateReview(author, model.Review{
+		MovieID: request.MovieID,
+		UserID:  &author.UserID,
+		Comment: request.Comment,
+		Score:   request.Score,
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create review"})
+		respondReviewError(c, err, "Failed to create review")
 		return
 	}
 	c.JSON(http.StatusCreated, created)
 }
 
+// GetThread godoc
+// @Summary Get a review thread
+// @Description Get a review with its tree of approved replies, oldest first. Deleted reviews that still have replies appear as "[deleted]".
+// @Tags reviews
+// @Produce json
+// @Param id path int true "Review ID"
+// @Success 200 {object} dto.ReviewThread
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /reviews/{id}/thread [get]
+func (h *ReviewHandler) GetThread(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid review ID"})
+		return
+	}
+	thread, err := h.reviewService.GetThread(id)
+	if err != nil {
+		respondReviewError(c, err, "Failed to fetch thread")
+		return
+	}
+	c.JSON(http.StatusOK, thread)
+}
+
+// ReplyToReview godoc
+// @Summary Reply to a review
+// @Description Add a reply below a review or another reply. Replies have no score and nest a limited number of levels.
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path int true "ID of the review or reply to answer"
+// @Param reply body dto.ReplyRequest true "Reply"
+// @Success 201 {object} model.Review
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /reviews/{id}/replies [post]
+func (h *ReviewHandler) ReplyToReview(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid review ID"})
+		return
+	}
+	var request dto.ReplyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	author, _ := middleware.CurrentUser(c)
+	reply, err := h.reviewService.ReplyToReview(author, id, request.Comment)
+	if err != nil {
+		respondReviewError(c, err, "Failed to create reply")
+		return
+	}
+	c.JSON(http.StatusCreated, reply)
+}
+
+// UpdateReview godoc
+// @Summary Update a review
+// @Description Replace the comment and score of a review. Only the author or a moderator may do this.
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path int true "Review ID"
+// @Param review body dto.ReviewUpdateRequest true "New comment and score"
+// @Success 200 {object} model.Review
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /reviews/{id} [put]
+func (h *ReviewHandler) UpdateReview(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid review ID"})
+		return
+	}
+	var request dto.ReviewUpdateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	actor, _ := middleware.CurrentUser(c)
+	updated, err := h.reviewService.UpdateReview(actor, id, request)
+	if err != nil {
+		respondReviewError(c, err, "Failed to update review")
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
 // DeleteReview godoc
 // @Summary Delete a review
-// @Description Delete a review by ID
+// @Description Delete a review by ID. Only the author or a moderator may do this. A review with replies is replaced by a "[deleted]" placeholder.
 // @Tags reviews
 // @Param id path int true "Review ID"
 // @Success 204
 // @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
 // @Failure 500 {object} map[string]string
+// @Security BearerAuth
 // @Router /reviews/{id} [delete]
 func (h *ReviewHandler) DeleteReview(c *gin.Context) {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
@@ -81,9 +217,103 @@ func (h *ReviewHandler) DeleteReview(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid review ID"})
 		return
 	}
-	if err := h.reviewService.DeleteReview(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete review"})
+	actor, _ := middleware.CurrentUser(c)
+	if err := h.reviewService.DeleteReview(actor, id); err != nil {
+		respondReviewError(c, err, "Failed to delete review")
 		return
 	}
 	c.JSON(http.StatusNoContent, nil)
 }
+
+// VoteReview godoc
+// @Summary Vote on a review
+// @Description Mark a review as helpful or unhelpful. Voting again replaces the earlier vote.
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path int true "Review ID"
+// @Param vote body dto.ReviewVoteRequest true "Vote"
+// @Success 200 {object} model.Review
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /reviews/{id}/vote [post]
+func (h *ReviewHandler) VoteReview(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid review ID"})
+		return
+	}
+	var request dto.ReviewVoteRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	voter, _ := middleware.CurrentUser(c)
+	review, err := h.reviewService.VoteReview(voter, id, request.Helpful)
+	if err != nil {
+		respondReviewError(c, err, "Failed to record vote")
+		return
+	}
+	c.JSON(http.StatusOK, review)
+}
+
+// ReportReview godoc
+// @Summary Report a review
+// @Description Flag a review as abusive. Reviews reported by enough users go back to moderation.
+// @Tags reviews
+// @Accept json
+// @Param id path int true "Review ID"
+// @Param report body dto.ReviewReportRequest false "Optional reason"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /reviews/{id}/report [post]
+func (h *ReviewHandler) ReportReview(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid review ID"})
+		return
+	}
+	// The body is optional
+	var request dto.ReviewReportRequest
+	if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	reporter, _ := middleware.CurrentUser(c)
+	if err := h.reviewService.ReportReview(reporter, id, request.Reason); err != nil {
+		respondReviewError(c, err, "Failed to report review")
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func respondReviewError(c *gin.Context, err error, message string) {
+	var validationErr *service.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		respondValidationError(c, err)
+	case errors.Is(err, service.ErrReviewNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
+	case errors.Is(err, service.ErrMovieNotFound):
+		// The movie comes from the request body, so the request itself is at fault
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Movie not found", "field": "movie_id"})
+	case errors.Is(err, service.ErrForbidden):
+		middleware.AbortForbidden(c, "only the author or a moderator can change this review")
+	case errors.Is(err, service.ErrOwnReview):
+		middleware.AbortForbidden(c, err.Error())
+	case errors.Is(err, service.ErrAlreadyReported):
+		c.JSON(http.StatusConflict, gin.H{"error": "Review already reported"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
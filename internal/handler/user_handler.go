@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Cladkoewka/movie-manager/internal/middleware"
+	"github.com/Cladkoewka/movie-manager/internal/model/dto"
+	"github.com/Cladkoewka/movie-manager/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type UserHandler struct {
+	userService *service.UserService
+}
+
+func NewUserHandler(userService *service.UserService) *UserHandler {
+	return &UserHandler{userService: userService}
+}
+
+// GetAllUsers godoc
+// @Summary Get all users
+// @Description Get every user account with its role
+// @Tags admin
+// @Produce json
+// @Success 200 {array} model.User
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /admin/users [get]
+func (h *UserHandler) GetAllUsers(c *gin.Context) {
+	users, err := h.userService.GetAllUsers()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		return
+	}
+	c.JSON(http.StatusOK, users)
+}
+
+// GrantRole godoc
+// @Summary Grant a role
+// @Description Set the role of a user (user, moderator, editor or admin)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int64 true "User ID"
+// @Param request body dto.RoleRequest true "Role to grant"
+// @Success 200 {object} model.User
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /admin/users/{id}/role [put]
+func (h *UserHandler) GrantRole(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	var request dto.RoleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	actor, _ := middleware.CurrentUser(c)
+	user, err := h.userService.GrantRole(actor, userID, request.Role)
+	if err != nil {
+		respondUserError(c, err, "Failed to grant role")
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// RevokeRole godoc
+// @Summary Revoke a role
+// @Description Return a user to the default 'user' role
+// @Tags admin
+// @Produce json
+// @Param id path int64 true "User ID"
+// @Success 200 {object} model.User
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /admin/users/{id}/role [delete]
+func (h *UserHandler) RevokeRole(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	actor, _ := middleware.CurrentUser(c)
+	user, err := h.userService.RevokeRole(actor, userID)
+	if err != nil {
+		respondUserError(c, err, "Failed to revoke role")
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+func respondUserError(c *gin.Context, err error, message string) {
+	var validationErr *service.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		respondValidationError(c, err)
+	case errors.Is(err, service.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	case errors.Is(err, service.ErrOwnRoleChange):
+		middleware.AbortForbidden(c, err.Error())
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
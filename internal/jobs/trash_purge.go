@@ -0,0 +1,41 @@
+// Package jobs runs periodic background work next to the HTTP server
+package jobs
+
+import (
+	"log"
+	"time"
+)
+
+// TrashPurger permanently removes trashed items older than retention
+type TrashPurger interface {
+	PurgeTrash(retention time.Duration) (int64, error)
+}
+
+// StartTrashPurge purges the trash once at startup and then every interval.
+// A zero retention or interval disables the job.
+func StartTrashPurge(purger TrashPurger, retention, interval time.Duration) {
+	if retention <= 0 || interval <= 0 {
+		log.Println("Trash purge disabled")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			purgeTrash(purger, retention)
+			<-ticker.C
+		}
+	}()
+}
+
+func purgeTrash(purger TrashPurger, retention time.Duration) {
+	purged, err := purger.PurgeTrash(retention)
+	if err != nil {
+		log.Printf("Failed to purge trash: %v", err)
+		return
+	}
+	if purged > 0 {
+		log.Printf("Purged %d items deleted more than %s ago", purged, retention)
+	}
+}
@@ -5,11 +5,19 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
 
+	"github.com/Cladkoewka/movie-manager/internal/auth"
 	"github.com/Cladkoewka/movie-manager/internal/model"
 	"github.com/Cladkoewka/movie-manager/internal/service"
 )
 
+// legacyMovie is the dump format, where genre is a single comma-separated string
+type legacyMovie struct {
+	model.Movie
+	Genre string `json:"genre"`
+}
+
 func LoadMoviesFromJSON(movieService *service.MovieService, filePath string) error {
 	file, err := os.Open(filePath)
 	if err != nil {
@@ -22,13 +30,21 @@ func LoadMoviesFromJSON(movieService *service.MovieService, filePath string) err
 		return fmt.Errorf("failed to read file: %w", err)
 	}
 
-	var movies []model.Movie
+	var movies []legacyMovie
 	if err := json.Unmarshal(data, &movies); err != nil {
 		return fmt.Errorf("failed to unmarshal JSON: %w", err)
 	}
 
-	for _, movie := range movies {
-		if _, err := movieService.CreateMovie(movie); err != nil {
+	for _, legacy := range movies {
+		movie := legacy.Movie
+		if len(movie.Genres) == 0 {
+			for _, name := range strings.Split(legacy.Genre, ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					movie.Genres = append(movie.Genres, model.Genre{Name: name})
+				}
+			}
+		}
+		if _, err := movieService.CreateMovie(auth.System, movie); err != nil {
 			return fmt.Errorf("failed to create movie: %w", err)
 		}
 	}
@@ -54,7 +70,7 @@ func LoadReviewsFromJSON(reviewService *service.ReviewService, filePath string)
 	}
 
 	for _, review := range reviews {
-		if _, err := reviewService.CreateReview(review); err != nil {
+		if _, err := reviewService.CreateReview(auth.System, review); err != nil {
 			return fmt.Errorf("ошибка при создании отзыва: %w", err)
 		}
 	}
@@ -0,0 +1,74 @@
+package mergepatch
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// The examples from RFC 7396, Appendix A
+func TestApplyRFCExamples(t *testing.T) {
+	tests := []struct {
+		document, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tt := range tests {
+		got, err := Apply([]byte(tt.document), []byte(tt.patch))
+		if err != nil {
+			t.Errorf("Apply(%s, %s): %v", tt.document, tt.patch, err)
+			continue
+		}
+		if !jsonEqual(t, got, []byte(tt.want)) {
+			t.Errorf("Apply(%s, %s) = %s, want %s", tt.document, tt.patch, got, tt.want)
+		}
+	}
+}
+
+func TestApplyKeepsLargeNumbers(t *testing.T) {
+	got, err := Apply([]byte(`{"id":9007199254740993}`), []byte(`{"title":"Heat"}`))
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if want := `{"id":9007199254740993,"title":"Heat"}`; string(got) != want {
+		t.Errorf("Apply = %s, want %s", got, want)
+	}
+}
+
+func TestApplyRejectsInvalidPatch(t *testing.T) {
+	for _, patch := range []string{``, `{"a":`, `{"a":1} {"b":2}`} {
+		if _, err := Apply([]byte(`{}`), []byte(patch)); !errors.Is(err, ErrInvalidPatch) {
+			t.Errorf("Apply(%q) error = %v, want ErrInvalidPatch", patch, err)
+		}
+	}
+	if _, err := Apply([]byte(`{`), []byte(`{}`)); err == nil || errors.Is(err, ErrInvalidPatch) {
+		t.Errorf("Apply with an invalid document: error = %v, want a document error", err)
+	}
+}
+
+func jsonEqual(t *testing.T, a, b []byte) bool {
+	t.Helper()
+	var left, right interface{}
+	if err := json.Unmarshal(a, &left); err != nil {
+		t.Fatalf("json.Unmarshal(%s): %v", a, err)
+	}
+	if err := json.Unmarshal(b, &right); err != nil {
+		t.Fatalf("json.Unmarshal(%s): %v", b, err)
+	}
+	return reflect.DeepEqual(left, right)
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/Cladkoewka/movie-manager/internal/auth"
+	"github.com/gin-gonic/gin"
+)
+
+const principalContextKey = "principal"
+
+// Authenticator turns an access token into the caller it was issued to. It
+// returns auth.ErrInvalidToken when the token or its user is not valid.
+type Authenticator interface {
+	Authenticate(token string) (auth.Principal, error)
+}
+
+// RequireAuth rejects requests without a valid "Authorization: Bearer <access token>"
+// header and stores the caller in the context for CurrentUser
+func RequireAuth(authenticator Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		token, found := strings.CutPrefix(header, "Bearer ")
+		if !found || token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing bearer token"})
+			return
+		}
+
+		principal, err := authenticator.Authenticate(token)
+		if errors.Is(err, auth.ErrInvalidToken) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			return
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to authenticate"})
+			return
+		}
+
+		c.Set(principalContextKey, principal)
+		c.Next()
+	}
+}
+
+// RequirePermission lets the request through only when the role of the
+// caller grants the permission. It must run after RequireAuth.
+func RequirePermission(permission auth.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := CurrentUser(c)
+		if !ok || !principal.Can(permission) {
+			AbortForbidden(c, "requires permission "+string(permission))
+			return
+		}
+		c.Next()
+	}
+}
+
+// AbortForbidden writes the 403 body shared by route policies and handlers
+func AbortForbidden(c *gin.Context, details string) {
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Forbidden", "details": details})
+}
+
+// CurrentUser returns the caller authenticated by RequireAuth
+func CurrentUser(c *gin.Context) (auth.Principal, bool) {
+	value, ok := c.Get(principalContextKey)
+	if !ok {
+		return auth.Principal{}, false
+	}
+	principal, ok := value.(auth.Principal)
+	return principal, ok
+}
@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+
+	"github.com/Cladkoewka/movie-manager/internal/diff"
+)
+
+// AuditEntry records one change to the catalog. Entries are only ever
+// inserted; the table rejects updates and deletes.
+type AuditEntry struct {
+	ID        int64        `json:"id"`
+	ActorID   *int64       `json:"actor_id" gorm:"index"`                                 // nil for changes made by the server itself
+	Actor     string       `json:"actor" gorm:"not null"`                                 // Username at the time of the change
+	Entity    string       `json:"entity" gorm:"not null;index:idx_audit_entries_entity"` // movie, review, poster or trailer
+	EntityID  int64        `json:"entity_id" gorm:"not null;index:idx_audit_entries_entity"`
+	Action    string       `json:"action" gorm:"not null"` // create, update, delete or restore
+	Diff      diff.Changes `json:"diff" gorm:"type:jsonb;not null"`
+	CreatedAt time.Time    `json:"created_at" gorm:"not null;default:CURRENT_TIMESTAMP;index"`
+}
@@ -0,0 +1,24 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Cladkoewka/movie-manager/internal/model"
+)
+
+// AuditQueryParams filters the audit log. Zero values match everything.
+type AuditQueryParams struct {
+	Entity   string
+	EntityID *int64
+	ActorID  *int64
+	Actor    string // Username
+	From     *time.Time
+	To       *time.Time
+	Page     int
+	PageSize int
+}
+
+type AuditLogResponse struct {
+	Entries []model.AuditEntry `json:"entries"`
+	Total   int64              `json:"total"`
+}
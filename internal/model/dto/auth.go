@@ -0,0 +1,29 @@
+package dto
+
+import "github.com/Cladkoewka/movie-manager/internal/model"
+
+type RegisterRequest struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// AuthResponse carries a fresh token pair. The access token goes into the
+// Authorization header as "Bearer <token>"; the refresh token is exchanged
+// for a new pair at /auth/refresh once the access token expires.
+type AuthResponse struct {
+	User         model.User `json:"user"`
+	AccessToken  string     `json:"access_token"`
+	RefreshToken string     `json:"refresh_token"`
+	TokenType    string     `json:"token_type"`
+	ExpiresIn    int64      `json:"expires_in"` // Access token lifetime in seconds
+}
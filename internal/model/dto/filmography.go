@@ -0,0 +1,10 @@
+package dto
+
+import "github.com/Cladkoewka/movie-manager/internal/model"
+
+// Filmography lists a person's credits with the movies they belong to,
+// newest release first
+type Filmography struct {
+	Person  model.Person        `json:"person"`
+	Credits []model.MovieCredit `json:"credits"`
+}
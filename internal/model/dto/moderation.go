@@ -0,0 +1,12 @@
+package dto
+
+import "github.com/Cladkoewka/movie-manager/internal/model"
+
+type ModerationQueueResponse struct {
+	Reviews []model.Review `json:"reviews"`
+	Total   int64          `json:"total"`
+}
+
+type ModerationRequest struct {
+	Reason string `json:"reason"` // Optional note shown to the author
+}
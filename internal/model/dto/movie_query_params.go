@@ -1,12 +1,34 @@
 package dto
 
+import "time"
+
 type MovieQueryParams struct {
-	Search   string   `json:"search,omitempty"` // Search term for movie title
-	Genre    string   `json:"genre,omitempty"`
-	Language string   `json:"language,omitempty"`
-	Rating   *float64 `json:"rating,omitempty"`
-	SortBy   string   `json:"sortBy,omitempty"`
-	OrderBy  string   `json:"orderBy,omitempty"`
-	Page     int      `json:"page,omitempty"`
-	PageSize int      `json:"pageSize,omitempty"`
+	Search         string      `json:"search,omitempty"`         // Search term for movie title
+	SearchMode     string      `json:"searchMode,omitempty"`     // "title" (ILIKE on title) or "fulltext"
+	Fuzzy          bool        `json:"fuzzy,omitempty"`          // Match titles by trigram similarity
+	FuzzyThreshold float64     `json:"fuzzyThreshold,omitempty"` // Minimum similarity in (0, 1]
+	Genres         []string    `json:"genres,omitempty"`         // Any of the values may match
+	Languages      []string    `json:"languages,omitempty"`
+	Directors      []string    `json:"directors,omitempty"`
+	Match          string      `json:"match,omitempty"`  // "contains" or "exact" for genre, language and director
+	Rating         *float64    `json:"rating,omitempty"` // Minimum rating
+	MaxRating      *float64    `json:"maxRating,omitempty"`
+	MinUserRating  *float64    `json:"minUserRating,omitempty"` // Bounds on the average review score
+	MaxUserRating  *float64    `json:"maxUserRating,omitempty"`
+	MinRatingCount *int        `json:"minRatingCount,omitempty"` // Minimum number of scored reviews
+	ReleasedFrom   *time.Time  `json:"releasedFrom,omitempty"`   // Inclusive release date bounds
+	ReleasedTo     *time.Time  `json:"releasedTo,omitempty"`
+	Year           *int        `json:"year,omitempty"`
+	MinDuration    *int        `json:"minDuration,omitempty"` // Duration bounds in minutes
+	MaxDuration    *int        `json:"maxDuration,omitempty"`
+	Sort           []SortField `json:"sort,omitempty"`   // Applied in order, id is always the final tiebreaker
+	Facets         []string    `json:"facets,omitempty"` // Facet counts to compute, see constants.AllowedFacets
+	Page           int         `json:"page,omitempty"`
+	PageSize       int         `json:"pageSize,omitempty"`
+	Cursor         string      `json:"cursor,omitempty"` // Opaque keyset cursor; Page is ignored when set
+}
+
+type SortField struct {
+	Field string `json:"field"`
+	Desc  bool   `json:"desc,omitempty"`
 }
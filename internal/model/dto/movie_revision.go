@@ -0,0 +1,24 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Cladkoewka/movie-manager/internal/diff"
+	"github.com/Cladkoewka/movie-manager/internal/model"
+)
+
+type MovieRevisionsResponse struct {
+	Revisions []model.MovieRevision `json:"revisions"`
+	Total     int64                 `json:"total"`
+}
+
+// MovieRevisionDiff lists the fields a revision changed compared to the one before it
+type MovieRevisionDiff struct {
+	MovieID          int64        `json:"movie_id"`
+	Revision         int          `json:"revision"`
+	PreviousRevision *int         `json:"previous_revision"` // nil for the first revision, which lists every field
+	AuthorID         *int64       `json:"author_id"`
+	Author           string       `json:"author"`
+	CreatedAt        time.Time    `json:"created_at"`
+	Changes          diff.Changes `json:"changes"`
+}
@@ -3,6 +3,29 @@ package dto
 import "github.com/Cladkoewka/movie-manager/internal/model"
 
 type MoviesResponse struct {
-	Movies []model.Movie `json:"movies"`
-	Total  int64           `json:"total"`
-}
\ No newline at end of file
+	Movies     []model.Movie    `json:"movies"`
+	Total      int64            `json:"total"`
+	Highlights []MovieHighlight `json:"highlights,omitempty"`
+	// Fuzzy is set when movies were matched by title similarity,
+	// either on request or as a fallback for a search without exact matches
+	Fuzzy bool `json:"fuzzy,omitempty"`
+	// Facets holds the number of matching movies per value for each requested facet
+	Facets map[string][]FacetCount `json:"facets,omitempty"`
+	// Cursors for the neighbouring pages; empty when there is no such page
+	// or the sort order is a computed score (relevance, fuzzy similarity)
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// MovieHighlight shows why a movie matched a full-text search.
+// Matched words are wrapped in <mark> tags.
+type MovieHighlight struct {
+	MovieID int64  `json:"movie_id"`
+	Title   string `json:"title"`
+	Snippet string `json:"snippet"`
+}
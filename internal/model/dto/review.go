@@ -0,0 +1,24 @@
+package dto
+
+type ReviewRequest struct {
+	MovieID int64  `json:"movie_id"`
+	Comment string `json:"comment"`
+	Score   *int   `json:"score"` // 1-10, optional
+}
+
+type ReplyRequest struct {
+	Comment string `json:"comment"`
+}
+
+type ReviewVoteRequest struct {
+	Helpful bool `json:"helpful"`
+}
+
+type ReviewReportRequest struct {
+	Reason string `json:"reason"`
+}
+
+type ReviewUpdateRequest struct {
+	Comment string `json:"comment"`
+	Score   *int   `json:"score"` // Omitting the score clears it
+}
@@ -0,0 +1,8 @@
+package dto
+
+type ReviewQueryParams struct {
+	Sort     string `json:"sort,omitempty"` // newest, oldest, highest, lowest or helpful
+	Page     int    `json:"page,omitempty"`
+	PageSize int    `json:"pageSize,omitempty"`
+	Cursor   string `json:"cursor,omitempty"` // Opaque keyset cursor; Page is ignored when set
+}
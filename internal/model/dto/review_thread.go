@@ -0,0 +1,9 @@
+package dto
+
+import "github.com/Cladkoewka/movie-manager/internal/model"
+
+// ReviewThread is a review with its approved replies, oldest first
+type ReviewThread struct {
+	model.Review
+	Replies []ReviewThread `json:"replies"`
+}
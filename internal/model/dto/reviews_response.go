@@ -0,0 +1,20 @@
+package dto
+
+import "github.com/Cladkoewka/movie-manager/internal/model"
+
+type ReviewsResponse struct {
+	Reviews []model.Review `json:"reviews"`
+	Total   int64          `json:"total"`
+	// AverageScore and ScoreHistogram cover every scored review of the
+	// movie, not only the current page
+	AverageScore   float64      `json:"average_score"`
+	ScoreHistogram []ScoreCount `json:"score_histogram"`
+	NextCursor     string       `json:"next_cursor,omitempty"`
+	PrevCursor     string       `json:"prev_cursor,omitempty"`
+}
+
+// ScoreCount is one bar of the histogram; every score from 1 to 10 is present
+type ScoreCount struct {
+	Score int   `json:"score"`
+	Count int64 `json:"count"`
+}
@@ -0,0 +1,6 @@
+package model
+
+type Genre struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name" gorm:"not null"`
+}
@@ -1,16 +1,25 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Movie struct {
 	ID int64 `json:"id"`
 	Title string `json:"title"`
 	Description string `json:"description"`
 	ReleaseDate time.Time `json:"release_date"`
-	Genre string `json:"genre"`
+	Genres []Genre `json:"genres" gorm:"many2many:movie_genres;constraint:OnDelete:CASCADE"`
 	Director string `json:"director"`
 	Rating float64 `json:"rating"`
 	Duration int `json:"duration"`
 	Language string `json:"language"`
 	TrailerURL string `json:"trailer_url"`
+	AverageUserRating float64 `json:"average_user_rating" gorm:"not null;default:0"` // Mean review score, kept in sync by the review repository
+	RatingCount int `json:"rating_count" gorm:"not null;default:0"`
+	Version int64 `json:"version" gorm:"not null;default:1"` // Raised by every update, see the ETag on GET /movies/{id}
+	CreatedAt time.Time `json:"created_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index" swaggertype:"string" format:"date-time"` // Set while the movie is in the trash
 }
\ No newline at end of file
@@ -1,11 +1,17 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type MoviePoster struct {
-	ID        int64     `json:"id"`
-	MovieID   int64     `json:"movie_id"`
-	Poster    []byte    `json:"poster"`    
-	MimeType  string    `json:"mime_type"` 
-	CreatedAt time.Time `json:"created_at"`
+	ID        int64          `json:"id"`
+	MovieID   int64          `json:"movie_id" gorm:"not null;index"`
+	Movie     *Movie         `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	Poster    []byte         `json:"poster"`
+	MimeType  string         `json:"mime_type"`
+	CreatedAt time.Time      `json:"created_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 }
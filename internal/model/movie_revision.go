@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// MovieRevision is a snapshot of a movie as it was saved by one create or
+// update. Revisions are numbered per movie starting at 1.
+type MovieRevision struct {
+	ID        int64     `json:"id"`
+	MovieID   int64     `json:"movie_id" gorm:"not null;uniqueIndex:idx_movie_revisions_movie_revision"`
+	Movie     *Movie    `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	Revision  int       `json:"revision" gorm:"not null;uniqueIndex:idx_movie_revisions_movie_revision"`
+	Snapshot  Movie     `json:"snapshot" gorm:"type:jsonb;serializer:json;not null"`
+	AuthorID  *int64    `json:"author_id"` // nil for changes made by the server itself and for the baseline of older movies
+	Author    string    `json:"author"`
+	CreatedAt time.Time `json:"created_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
+}
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+type Person struct {
+	ID        int64      `json:"id"`
+	Name      string     `json:"name" gorm:"not null;index"`
+	BirthDate *time.Time `json:"birth_date,omitempty"`
+	Biography string     `json:"biography"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// MovieCredit links a person to a movie in a role such as director or actor
+type MovieCredit struct {
+	ID            int64   `json:"id"`
+	MovieID       int64   `json:"movie_id" gorm:"not null;index"`
+	Movie         *Movie  `json:"movie,omitempty" gorm:"constraint:OnDelete:CASCADE"`
+	PersonID      int64   `json:"person_id" gorm:"not null;index"`
+	Person        *Person `json:"person,omitempty" gorm:"constraint:OnDelete:CASCADE"`
+	Role          string  `json:"role" gorm:"not null"`
+	CharacterName string  `json:"character_name,omitempty"`
+	BillingOrder  int     `json:"billing_order"`
+}
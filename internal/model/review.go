@@ -1,7 +1,30 @@
 package model
 
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
 type Review struct {
-	ID int64 `json:"id"`
-	MovieID int64 `json:"movie_id"` 
-	Comment string `json:"comment"`
-}
\ No newline at end of file
+	ID               int64          `json:"id"`
+	MovieID          int64          `json:"movie_id" gorm:"not null;index"`
+	Movie            *Movie         `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	UserID           *int64         `json:"user_id" gorm:"index"`             // nil for imported and deleted reviews
+	ParentID         *int64         `json:"parent_id,omitempty" gorm:"index"` // Set on replies
+	Parent           *Review        `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	Depth            int            `json:"depth" gorm:"not null;default:0"`       // 0 for reviews, parent depth + 1 for replies
+	Deleted          bool           `json:"deleted" gorm:"not null;default:false"` // Tombstone kept so replies stay attached
+	Comment          string         `json:"comment"`
+	Score            *int           `json:"score" gorm:"check:chk_reviews_score,score BETWEEN 1 AND 10"` // 1-10, optional
+	Status           string         `json:"status" gorm:"not null;default:approved;index"`               // pending, approved or rejected
+	ModerationReason string         `json:"moderation_reason,omitempty"`                                 // Set by the filters or a moderator
+	ModeratedBy      *int64         `json:"moderated_by,omitempty"`
+	ModeratedAt      *time.Time     `json:"moderated_at,omitempty"`
+	HelpfulCount     int            `json:"helpful_count" gorm:"not null;default:0"` // Vote and report counters, kept in sync by the review repository
+	UnhelpfulCount   int            `json:"unhelpful_count" gorm:"not null;default:0"`
+	ReportCount      int            `json:"report_count" gorm:"not null;default:0"`
+	CreatedAt        time.Time      `json:"created_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
+	UpdatedAt        time.Time      `json:"updated_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
+	DeletedAt        gorm.DeletedAt `json:"-" gorm:"index"` // Soft delete; Deleted marks tombstones that stay visible
+}
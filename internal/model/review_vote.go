@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// ReviewVote is one user's helpful or unhelpful mark on a review
+type ReviewVote struct {
+	ID        int64     `json:"id"`
+	ReviewID  int64     `json:"review_id" gorm:"not null;uniqueIndex:idx_review_votes_review_user"`
+	Review    *Review   `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	UserID    int64     `json:"user_id" gorm:"not null;uniqueIndex:idx_review_votes_review_user"`
+	User      *User     `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	Helpful   bool      `json:"helpful"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ReviewReport flags a review as abusive. Each user can report a review once.
+type ReviewReport struct {
+	ID        int64     `json:"id"`
+	ReviewID  int64     `json:"review_id" gorm:"not null;uniqueIndex:idx_review_reports_review_user"`
+	Review    *Review   `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	UserID    int64     `json:"user_id" gorm:"not null;uniqueIndex:idx_review_reports_review_user"`
+	User      *User     `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+type User struct {
+	ID           int64     `json:"id"`
+	Username     string    `json:"username" gorm:"uniqueIndex;not null"`
+	Email        string    `json:"email" gorm:"uniqueIndex;not null"`
+	PasswordHash string    `json:"-" gorm:"not null"`
+	Role         string    `json:"role" gorm:"not null;default:user"`
+	CreatedAt    time.Time `json:"created_at"`
+}
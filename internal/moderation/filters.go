@@ -0,0 +1,113 @@
+package moderation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// BannedWordsFilter rejects comments containing any of the words.
+// Only whole words match, so "class" is not caught by "ass". Word
+// boundaries are Unicode-aware, as \b only knows ASCII letters.
+type BannedWordsFilter struct {
+	pattern *regexp.Regexp
+}
+
+func NewBannedWordsFilter(words []string) *BannedWordsFilter {
+	var quoted []string
+	for _, word := range words {
+		if word = strings.TrimSpace(word); word != "" {
+			quoted = append(quoted, regexp.QuoteMeta(word))
+		}
+	}
+	if len(quoted) == 0 {
+		return &BannedWordsFilter{}
+	}
+	return &BannedWordsFilter{pattern: regexp.MustCompile(`(?i)(?:^|[^\p{L}\p{N}_])(` + strings.Join(quoted, "|") + `)(?:$|[^\p{L}\p{N}_])`)}
+}
+
+func (f *BannedWordsFilter) Check(submission Submission) (Result, error) {
+	if f.pattern == nil {
+		return Result{Verdict: Approve}, nil
+	}
+	if match := f.pattern.FindStringSubmatch(submission.Comment); match != nil {
+		return Result{Verdict: Reject, Reason: fmt.Sprintf("contains banned word %q", strings.ToLower(match[1]))}, nil
+	}
+	return Result{Verdict: Approve}, nil
+}
+
+var linkPattern = regexp.MustCompile(`(?i)(https?://|www\.)\S+`)
+
+// LinkSpamFilter holds comments with more links than allowed for review
+type LinkSpamFilter struct {
+	maxLinks int
+}
+
+func NewLinkSpamFilter(maxLinks int) *LinkSpamFilter {
+	return &LinkSpamFilter{maxLinks: maxLinks}
+}
+
+func (f *LinkSpamFilter) Check(submission Submission) (Result, error) {
+	links := len(linkPattern.FindAllString(submission.Comment, -1))
+	if links > f.maxLinks {
+		return Result{Verdict: Hold, Reason: fmt.Sprintf("contains %d links, at most %d allowed", links, f.maxLinks)}, nil
+	}
+	return Result{Verdict: Approve}, nil
+}
+
+// ReviewHistory answers questions about a user's earlier reviews
+type ReviewHistory interface {
+	CountByUserSince(userID int64, since time.Time) (int64, error)
+	HasDuplicateComment(userID int64, comment string, excludeReviewID int64) (bool, error)
+}
+
+// DuplicateFilter rejects a comment the same user has already posted,
+// ignoring case and surrounding whitespace
+type DuplicateFilter struct {
+	history ReviewHistory
+}
+
+func NewDuplicateFilter(history ReviewHistory) *DuplicateFilter {
+	return &DuplicateFilter{history: history}
+}
+
+func (f *DuplicateFilter) Check(submission Submission) (Result, error) {
+	if submission.UserID == nil {
+		return Result{Verdict: Approve}, nil
+	}
+	duplicate, err := f.history.HasDuplicateComment(*submission.UserID, submission.Comment, submission.ReviewID)
+	if err != nil {
+		return Result{}, err
+	}
+	if duplicate {
+		return Result{Verdict: Reject, Reason: "duplicates an earlier review by the same user"}, nil
+	}
+	return Result{Verdict: Approve}, nil
+}
+
+// RateFilter holds new reviews from users who post more than limit
+// reviews within window. Edits are not counted.
+type RateFilter struct {
+	history ReviewHistory
+	limit   int
+	window  time.Duration
+}
+
+func NewRateFilter(history ReviewHistory, limit int, window time.Duration) *RateFilter {
+	return &RateFilter{history: history, limit: limit, window: window}
+}
+
+func (f *RateFilter) Check(submission Submission) (Result, error) {
+	if submission.UserID == nil || submission.ReviewID != 0 {
+		return Result{Verdict: Approve}, nil
+	}
+	count, err := f.history.CountByUserSince(*submission.UserID, time.Now().Add(-f.window))
+	if err != nil {
+		return Result{}, err
+	}
+	if count >= int64(f.limit) {
+		return Result{Verdict: Hold, Reason: fmt.Sprintf("more than %d reviews within %s", f.limit, f.window)}, nil
+	}
+	return Result{Verdict: Approve}, nil
+}
@@ -0,0 +1,40 @@
+package moderation
+
+import "testing"
+
+func TestBannedWordsFilter(t *testing.T) {
+	f := NewBannedWordsFilter([]string{"ass", "плохо", " дрянь "})
+
+	tests := []struct {
+		comment string
+		verdict Verdict
+		reason  string
+	}{
+		{"What an ass", Reject, `contains banned word "ass"`},
+		{"A classic", Approve, ""},
+		{"Очень плохо снято", Reject, `contains banned word "плохо"`},
+		{"ПЛОХО!", Reject, `contains banned word "плохо"`},
+		{"Дрянь, а не фильм", Reject, `contains banned word "дрянь"`},
+		{"Неплохо снято", Approve, ""},
+		{"плохой фильм", Approve, ""},
+		{"плохо_снято", Approve, ""},
+		{"плохо2", Approve, ""},
+	}
+	for _, tt := range tests {
+		got, err := f.Check(Submission{Comment: tt.comment})
+		if err != nil {
+			t.Fatalf("Check(%q): %v", tt.comment, err)
+		}
+		if got.Verdict != tt.verdict || got.Reason != tt.reason {
+			t.Errorf("Check(%q) = %+v, want %v %q", tt.comment, got, tt.verdict, tt.reason)
+		}
+	}
+}
+
+func TestBannedWordsFilterWithoutWords(t *testing.T) {
+	f := NewBannedWordsFilter([]string{"", "  "})
+	got, err := f.Check(Submission{Comment: "anything"})
+	if err != nil || got.Verdict != Approve {
+		t.Errorf("Check = %+v, %v, want Approve", got, err)
+	}
+}
@@ -0,0 +1,93 @@
+// Package moderation decides whether a submitted review is published,
+// held for a moderator or rejected, by running it through a chain of filters.
+package moderation
+
+import (
+	"strings"
+
+	"github.com/Cladkoewka/movie-manager/internal/constants"
+)
+
+// Verdict is the outcome of a filter. Verdicts are ordered by severity,
+// so the chain can keep the strictest one.
+type Verdict int
+
+const (
+	Approve Verdict = iota
+	Hold
+	Reject
+)
+
+// Status returns the review status a verdict leads to
+func (v Verdict) Status() string {
+	switch v {
+	case Hold:
+		return constants.ReviewStatusPending
+	case Reject:
+		return constants.ReviewStatusRejected
+	default:
+		return constants.ReviewStatusApproved
+	}
+}
+
+// Submission is the part of a review the filters look at
+type Submission struct {
+	ReviewID int64  // Set when an existing review is edited
+	UserID   *int64 // Nil for reviews without an author, e.g. imported ones
+	MovieID  int64
+	Comment  string
+}
+
+// Result is a filter's verdict with a reason a moderator can read
+type Result struct {
+	Verdict Verdict
+	Reason  string
+}
+
+// Filter inspects a submission. Filters that find nothing return Approve.
+type Filter interface {
+	Check(submission Submission) (Result, error)
+}
+
+// Decision combines the results of every filter in a chain
+type Decision struct {
+	Verdict Verdict
+	Reasons []string
+}
+
+func (d Decision) Status() string {
+	return d.Verdict.Status()
+}
+
+// Reason joins the reasons of all filters that flagged the submission
+func (d Decision) Reason() string {
+	return strings.Join(d.Reasons, "; ")
+}
+
+// Chain runs filters in order and keeps the strictest verdict
+type Chain struct {
+	filters []Filter
+}
+
+func NewChain(filters ...Filter) *Chain {
+	return &Chain{filters: filters}
+}
+
+// Evaluate runs every filter, so the decision lists all problems at once
+func (c *Chain) Evaluate(submission Submission) (Decision, error) {
+	decision := Decision{Verdict: Approve}
+	for _, filter := range c.filters {
+		result, err := filter.Check(submission)
+		if err != nil {
+			return Decision{}, err
+		}
+		if result.Verdict == Approve {
+			continue
+		}
+		if result.Verdict > decision.Verdict {
+			decision.Verdict = result.Verdict
+		}
+		decision.Reasons = append(decision.Reasons, result.Reason)
+	}
+	return decision, nil
+}
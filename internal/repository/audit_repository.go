@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"github.com/Cladkoewka/movie-manager/internal/model"
+	"github.com/Cladkoewka/movie-manager/internal/model/dto"
+	"gorm.io/gorm"
+)
+
+// AuditFunc builds the audit entry for a change from the entity's state
+// before and after it; before is nil for creations and after is nil for
+// deletions. Writes call it inside their transaction and store the entry
+// there, so a change is never committed without its entry.
+type AuditFunc func(entity string, entityID int64, action string, before, after interface{}) (*model.AuditEntry, error)
+
+// AuditRepository reads the audit log. Entries are only ever added by the
+// writes they describe, see AuditFunc, and there is deliberately no way to
+// change or remove one.
+type AuditRepository interface {
+	Find(params dto.AuditQueryParams) ([]model.AuditEntry, int64, error)
+}
+
+type AuditRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &AuditRepositoryImpl{db: db}
+}
+
+// addAuditEntry stores the entry audit builds for a change made in tx
+func addAuditEntry(tx *gorm.DB, audit AuditFunc, entity string, entityID int64, action string, before, after interface{}) error {
+	entry, err := audit(entity, entityID, action, before, after)
+	if err != nil {
+		return err
+	}
+	return tx.Create(entry).Error
+}
+
+// Find returns a page of entries matching params, newest first
+func (r *AuditRepositoryImpl) Find(params dto.AuditQueryParams) ([]model.AuditEntry, int64, error) {
+	query := r.db.Model(&model.AuditEntry{})
+	if params.Entity != "" {
+		query = query.Where("entity = ?", params.Entity)
+	}
+	if params.EntityID != nil {
+		query = query.Where("entity_id = ?", *params.EntityID)
+	}
+	if params.ActorID != nil {
+		query = query.Where("actor_id = ?", *params.ActorID)
+	}
+	if params.Actor != "" {
+		query = query.Where("actor = ?", params.Actor)
+	}
+	if params.From != nil {
+		query = query.Where("created_at >= ?", *params.From)
+	}
+	if params.To != nil {
+		query = query.Where("created_at <= ?", *params.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	entries := []model.AuditEntry{}
+	err := query.Order("created_at DESC, id DESC").
+		Offset((params.Page - 1) * params.PageSize).
+		Limit(params.PageSize).
+		Find(&entries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
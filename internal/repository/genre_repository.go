@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/Cladkoewka/movie-manager/internal/cache"
+	"github.com/Cladkoewka/movie-manager/internal/model"
+	"gorm.io/gorm"
+)
+
+// ErrGenreNotFound is returned when a movie references a genre ID that does not exist
+var ErrGenreNotFound = errors.New("genre not found")
+
+type GenreRepository interface {
+	GetAll() ([]model.Genre, error)
+	GetByID(id int64) (*model.Genre, error)
+	Create(genre model.Genre) (*model.Genre, error)
+	Update(genre model.Genre) (*model.Genre, error)
+	Delete(id int64) error
+}
+
+type GenreRepositoryImpl struct {
+	db           *gorm.DB
+	cacheService cache.Cache
+}
+
+func NewGenreRepository(db *gorm.DB, cacheService cache.Cache) GenreRepository {
+	return &GenreRepositoryImpl{db: db, cacheService: cacheService}
+}
+
+func (r *GenreRepositoryImpl) GetAll() ([]model.Genre, error) {
+	var genres []model.Genre
+	err := r.db.Order("name").Find(&genres).Error
+	return genres, err
+}
+
+func (r *GenreRepositoryImpl) GetByID(id int64) (*model.Genre, error) {
+	var genre model.Genre
+	if err := r.db.First(&genre, id).Error; err != nil {
+		return nil, err
+	}
+	return &genre, nil
+}
+
+func (r *GenreRepositoryImpl) Create(genre model.Genre) (*model.Genre, error) {
+	if err := r.db.Create(&genre).Error; err != nil {
+		return nil, err
+	}
+	return &genre, nil
+}
+
+// Update renames a genre. Movies embed genre names, so cached lists are dropped.
+func (r *GenreRepositoryImpl) Update(genre model.Genre) (*model.Genre, error) {
+	result := r.db.Model(&model.Genre{}).Where("id = ?", genre.ID).Update("name", genre.Name)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	invalidateMoviesCache(r.cacheService)
+	return &genre, nil
+}
+
+// Delete removes the genre; its links to movies go with it through the join table's cascade
+func (r *GenreRepositoryImpl) Delete(id int64) error {
+	result := r.db.Delete(&model.Genre{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	invalidateMoviesCache(r.cacheService)
+	return nil
+}
+
+// resolveGenres turns the genres sent with a movie into stored rows. Genres
+// given by ID must exist; genres given only by name are matched
+// case-insensitively and created when missing.
+func resolveGenres(tx *gorm.DB, genres []model.Genre) ([]model.Genre, error) {
+	resolved := make([]model.Genre, 0, len(genres))
+	seen := make(map[int64]bool, len(genres))
+
+	for _, genre := range genres {
+		var stored model.Genre
+		switch {
+		case genre.ID != 0:
+			if err := tx.First(&stored, genre.ID).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil, ErrGenreNotFound
+				}
+				return nil, err
+			}
+		case strings.TrimSpace(genre.Name) != "":
+			name := strings.TrimSpace(genre.Name)
+			err := tx.Where("lower(name) = lower(?)", name).
+				Attrs(model.Genre{Name: name}).
+				FirstOrCreate(&stored).Error
+			if err != nil {
+				return nil, err
+			}
+		default:
+			continue
+		}
+
+		if !seen[stored.ID] {
+			seen[stored.ID] = true
+			resolved = append(resolved, stored)
+		}
+	}
+	return resolved, nil
+}
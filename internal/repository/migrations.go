@@ -0,0 +1,152 @@
+package repository
+
+import "gorm.io/gorm"
+
+// MigrateMovieSearch adds the generated full-text search column for movies
+// together with its GIN index. AutoMigrate cannot express generated columns,
+// so this runs as plain SQL after the movies table exists.
+func MigrateMovieSearch(db *gorm.DB) error {
+	statements := []string{
+		`ALTER TABLE movies ADD COLUMN IF NOT EXISTS search_vector tsvector
+			GENERATED ALWAYS AS (
+				setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+				setweight(to_tsvector('english', coalesce(director, '')), 'B') ||
+				setweight(to_tsvector('english', coalesce(description, '')), 'C')
+			) STORED`,
+		`CREATE INDEX IF NOT EXISTS idx_movies_search_vector ON movies USING GIN (search_vector)`,
+	}
+	return execAll(db, statements)
+}
+
+// MigrateMovieTrigram enables pg_trgm and indexes movie titles with trigrams.
+// The index serves both the fuzzy `title % ?` lookups and ILIKE substring search.
+func MigrateMovieTrigram(db *gorm.DB) error {
+	statements := []string{
+		`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+		`CREATE INDEX IF NOT EXISTS idx_movies_title_trgm ON movies USING GIN (title gin_trgm_ops)`,
+	}
+	return execAll(db, statements)
+}
+
+// MigrateGenreIndexes makes genre names unique regardless of case
+func MigrateGenreIndexes(db *gorm.DB) error {
+	statements := []string{
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_genres_name_lower ON genres (lower(name))`,
+	}
+	return execAll(db, statements)
+}
+
+// MigrateAuditLog makes the audit table append-only: updates, deletes and
+// truncation fail in the database, not just in the repository
+func MigrateAuditLog(db *gorm.DB) error {
+	statements := []string{
+		`CREATE OR REPLACE FUNCTION reject_audit_change() RETURNS trigger AS $$
+			BEGIN
+				RAISE EXCEPTION 'audit_entries is append-only';
+			END
+		$$ LANGUAGE plpgsql`,
+		`DROP TRIGGER IF EXISTS audit_entries_append_only ON audit_entries`,
+		`CREATE TRIGGER audit_entries_append_only BEFORE UPDATE OR DELETE ON audit_entries
+			FOR EACH ROW EXECUTE FUNCTION reject_audit_change()`,
+		`DROP TRIGGER IF EXISTS audit_entries_no_truncate ON audit_entries`,
+		`CREATE TRIGGER audit_entries_no_truncate BEFORE TRUNCATE ON audit_entries
+			FOR EACH STATEMENT EXECUTE FUNCTION reject_audit_change()`,
+	}
+	return execAll(db, statements)
+}
+
+// updateMovieRatingsSQL recomputes the review aggregates stored on movies.
+// Only approved, not deleted reviews with a score count towards either column.
+const updateMovieRatingsSQL = `UPDATE movies SET
+	average_user_rating = COALESCE((SELECT AVG(score) FROM reviews
+		WHERE reviews.movie_id = movies.id AND reviews.status = 'approved' AND reviews.deleted_at IS NULL), 0),
+	rating_count = (SELECT COUNT(score) FROM reviews
+		WHERE reviews.movie_id = movies.id AND reviews.status = 'approved' AND reviews.deleted_at IS NULL)`
+
+// RecomputeMovieRatings rebuilds average_user_rating and rating_count for
+// every movie, for existing data and after bulk changes to reviews
+func RecomputeMovieRatings(db *gorm.DB) error {
+	return db.Exec(updateMovieRatingsSQL).Error
+}
+
+// RemoveMovieOrphans deletes posters and reviews left behind by deleted
+// movies, so the foreign keys AutoMigrate adds to those tables can be created
+func RemoveMovieOrphans(db *gorm.DB) error {
+	var statements []string
+	for _, table := range []string{"movie_posters", "reviews"} {
+		if db.Migrator().HasTable(table) {
+			statements = append(statements, `DELETE FROM `+table+`
+				WHERE NOT EXISTS (SELECT 1 FROM movies WHERE movies.id = `+table+`.movie_id)`)
+		}
+	}
+	return execAll(db, statements)
+}
+
+// MigrateLegacyGenres moves the old free-text movies.genre column into the
+// genres table and the movie_genres join table, then drops the column.
+// Comma-separated values become separate genres. It is a no-op once the
+// column is gone.
+func MigrateLegacyGenres(db *gorm.DB) error {
+	if !db.Migrator().HasColumn("movies", "genre") {
+		return nil
+	}
+
+	statements := []string{
+		`INSERT INTO genres (name)
+			SELECT DISTINCT ON (lower(trim(legacy.name))) trim(legacy.name)
+			FROM movies, unnest(string_to_array(movies.genre, ',')) AS legacy(name)
+			WHERE trim(legacy.name) <> ''
+			ON CONFLICT DO NOTHING`,
+		`INSERT INTO movie_genres (movie_id, genre_id)
+			SELECT DISTINCT movies.id, genres.id
+			FROM movies
+			CROSS JOIN LATERAL unnest(string_to_array(movies.genre, ',')) AS legacy(name)
+			JOIN genres ON lower(genres.name) = lower(trim(legacy.name))
+			ON CONFLICT DO NOTHING`,
+		`ALTER TABLE movies DROP COLUMN genre`,
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		return execAll(tx, statements)
+	})
+}
+
+// BackfillDirectorCredits turns the movies.director strings into people and
+// director credits. Directors are matched to existing people by name, case
+// insensitively, and movies that already have a director credit are skipped,
+// so running it again only picks up new movies.
+func BackfillDirectorCredits(db *gorm.DB) error {
+	statements := []string{
+		`INSERT INTO people (name, biography, created_at)
+			SELECT DISTINCT ON (lower(trim(movies.director))) trim(movies.director), '', now()
+			FROM movies
+			WHERE trim(coalesce(movies.director, '')) <> ''
+				AND NOT EXISTS (
+					SELECT 1 FROM people WHERE lower(people.name) = lower(trim(movies.director))
+				)`,
+		`INSERT INTO movie_credits (movie_id, person_id, role, character_name, billing_order)
+			SELECT movies.id, person.id, 'director', '', 0
+			FROM movies
+			CROSS JOIN LATERAL (
+				SELECT people.id FROM people
+				WHERE lower(people.name) = lower(trim(movies.director))
+				ORDER BY people.id
+				LIMIT 1
+			) AS person
+			WHERE NOT EXISTS (
+				SELECT 1 FROM movie_credits
+				WHERE movie_credits.movie_id = movies.id AND movie_credits.role = 'director'
+			)`,
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		return execAll(tx, statements)
+	})
+}
+
+func execAll(db *gorm.DB, statements []string) error {
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/Cladkoewka/movie-manager/internal/constants"
+	"github.com/Cladkoewka/movie-manager/internal/model"
+	"github.com/Cladkoewka/movie-manager/internal/model/dto"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded
+// or was issued for a different sort order
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// sortKey is one column of the ORDER BY used for keyset pagination
+type sortKey struct {
+	Column string
+	Desc   bool
+}
+
+// movieSortValues reads the value of a sortable column from a loaded movie
+var movieSortValues = map[string]func(model.Movie) interface{}{
+	"id":           func(m model.Movie) interface{} { return m.ID },
+	"title":        func(m model.Movie) interface{} { return m.Title },
+	"rating":       func(m model.Movie) interface{} { return m.Rating },
+	"release_date": func(m model.Movie) interface{} { return m.ReleaseDate },
+	"duration":     func(m model.Movie) interface{} { return m.Duration },
+	"director":     func(m model.Movie) interface{} { return m.Director },
+	"created_at":   func(m model.Movie) interface{} { return m.CreatedAt },
+
+	"average_user_rating": func(m model.Movie) interface{} { return m.AverageUserRating },
+	"rating_count":        func(m model.Movie) interface{} { return m.RatingCount },
+}
+
+// movieCursor is the decoded form of the opaque cursor handed to clients.
+// It holds the sort key values of the row the next page starts after.
+type movieCursor struct {
+	Sort     string            `json:"s"`
+	Values   []json.RawMessage `json:"v"`
+	Backward bool              `json:"b,omitempty"`
+}
+
+// movieSortKeys returns the keyset columns for params, ending with id so
+// that every row has a unique position. Relevance and fuzzy orderings are
+// computed scores and cannot be used with cursors.
+func movieSortKeys(params dto.MovieQueryParams) ([]sortKey, bool) {
+	if params.Fuzzy {
+		return nil, false
+	}
+
+	keys := make([]sortKey, 0, len(params.Sort)+1)
+	for _, field := range params.Sort {
+		if field.Field == constants.SortByRelevance {
+			return nil, false
+		}
+		keys = append(keys, sortKey{Column: field.Field, Desc: field.Desc})
+	}
+	return append(keys, sortKey{Column: "id"}), true
+}
+
+func sortSignature(keys []sortKey) string {
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		if key.Desc {
+			parts[i] = "-" + key.Column
+		} else {
+			parts[i] = key.Column
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func encodeMovieCursor(keys []sortKey, movie model.Movie, backward bool) (string, error) {
+	cursor := movieCursor{Sort: sortSignature(keys), Backward: backward}
+	for _, key := range keys {
+		value, err := json.Marshal(movieSortValues[key.Column](movie))
+		if err != nil {
+			return "", err
+		}
+		cursor.Values = append(cursor.Values, value)
+	}
+
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeMovieCursor parses the cursor and converts its values back to the
+// Go types of the sort columns, so they are compared with the right SQL type
+func decodeMovieCursor(encoded string, keys []sortKey) (values []interface{}, backward bool, err error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, false, ErrInvalidCursor
+	}
+
+	var cursor movieCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, false, ErrInvalidCursor
+	}
+	if cursor.Sort != sortSignature(keys) || len(cursor.Values) != len(keys) {
+		return nil, false, ErrInvalidCursor
+	}
+
+	for i, key := range keys {
+		target := reflect.New(reflect.TypeOf(movieSortValues[key.Column](model.Movie{})))
+		if err := json.Unmarshal(cursor.Values[i], target.Interface()); err != nil {
+			return nil, false, ErrInvalidCursor
+		}
+		values = append(values, target.Elem().Interface())
+	}
+	return values, cursor.Backward, nil
+}
+
+// keysetCondition selects the rows that come after values in the order
+// given by keys, or before them when backward is set:
+// (a > x) OR (a = x AND b > y) OR ...
+func keysetCondition(keys []sortKey, values []interface{}, backward bool) (string, []interface{}) {
+	var conditions []string
+	var vars []interface{}
+
+	for i, key := range keys {
+		var parts []string
+		for j := 0; j < i; j++ {
+			parts = append(parts, keys[j].Column+" = ?")
+			vars = append(vars, values[j])
+		}
+
+		operator := ">"
+		if key.Desc != backward {
+			operator = "<"
+		}
+		parts = append(parts, key.Column+" "+operator+" ?")
+		vars = append(vars, values[i])
+
+		conditions = append(conditions, "("+strings.Join(parts, " AND ")+")")
+	}
+
+	return "(" + strings.Join(conditions, " OR ") + ")", vars
+}
+
+func orderBySortKeys(keys []sortKey, backward bool) []string {
+	columns := make([]string, len(keys))
+	for i, key := range keys {
+		if key.Desc != backward {
+			columns[i] = key.Column + " DESC"
+		} else {
+			columns[i] = key.Column + " ASC"
+		}
+	}
+	return columns
+}
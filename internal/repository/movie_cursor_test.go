@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Cladkoewka/movie-manager/internal/constants"
+	"github.com/Cladkoewka/movie-manager/internal/model"
+	"github.com/Cladkoewka/movie-manager/internal/model/dto"
+)
+
+func TestMovieCursorRoundTrip(t *testing.T) {
+	keys, ok := movieSortKeys(dto.MovieQueryParams{Sort: []dto.SortField{
+		{Field: "rating", Desc: true},
+		{Field: "release_date"},
+		{Field: "title"},
+	}})
+	if !ok {
+		t.Fatal("movieSortKeys refused a plain sort")
+	}
+
+	released := time.Date(1995, 12, 15, 0, 0, 0, 0, time.UTC)
+	movie := model.Movie{ID: 42, Title: "Heat", Rating: 8.3, ReleaseDate: released}
+	for _, backward := range []bool{false, true} {
+		encoded, err := encodeMovieCursor(keys, movie, backward)
+		if err != nil {
+			t.Fatalf("encodeMovieCursor: %v", err)
+		}
+		values, gotBackward, err := decodeMovieCursor(encoded, keys)
+		if err != nil {
+			t.Fatalf("decodeMovieCursor: %v", err)
+		}
+		want := []interface{}{8.3, released, "Heat", int64(42)}
+		if !reflect.DeepEqual(values, want) || gotBackward != backward {
+			t.Errorf("decodeMovieCursor = %#v, %v, want %#v, %v", values, gotBackward, want, backward)
+		}
+	}
+}
+
+func TestMovieCursorRejectsOtherSort(t *testing.T) {
+	byRating := []sortKey{{Column: "rating", Desc: true}, {Column: "id"}}
+	encoded, err := encodeMovieCursor(byRating, model.Movie{ID: 1, Rating: 7}, false)
+	if err != nil {
+		t.Fatalf("encodeMovieCursor: %v", err)
+	}
+
+	for name, keys := range map[string][]sortKey{
+		"other column":    {{Column: "title"}, {Column: "id"}},
+		"other direction": {{Column: "rating"}, {Column: "id"}},
+		"extra column":    {{Column: "rating", Desc: true}, {Column: "title"}, {Column: "id"}},
+	} {
+		if _, _, err := decodeMovieCursor(encoded, keys); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%s: error = %v, want ErrInvalidCursor", name, err)
+		}
+	}
+}
+
+func TestMovieCursorRejectsMalformed(t *testing.T) {
+	keys := []sortKey{{Column: "rating"}, {Column: "id"}}
+	for name, encoded := range map[string]string{
+		"not base64":  "!!!",
+		"not json":    base64.RawURLEncoding.EncodeToString([]byte("rating")),
+		"wrong type":  base64.RawURLEncoding.EncodeToString([]byte(`{"s":"rating,id","v":["high",1]}`)),
+		"short value": base64.RawURLEncoding.EncodeToString([]byte(`{"s":"rating,id","v":[7.5]}`)),
+	} {
+		if _, _, err := decodeMovieCursor(encoded, keys); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%s: error = %v, want ErrInvalidCursor", name, err)
+		}
+	}
+}
+
+func TestMovieSortKeysWithoutCursor(t *testing.T) {
+	if _, ok := movieSortKeys(dto.MovieQueryParams{Fuzzy: true}); ok {
+		t.Error("fuzzy search should not support cursors")
+	}
+	params := dto.MovieQueryParams{Sort: []dto.SortField{{Field: constants.SortByRelevance}}}
+	if _, ok := movieSortKeys(params); ok {
+		t.Error("relevance sort should not support cursors")
+	}
+}
+
+func TestKeysetCondition(t *testing.T) {
+	keys := []sortKey{{Column: "rating", Desc: true}, {Column: "id"}}
+	values := []interface{}{8.0, int64(5)}
+
+	tests := []struct {
+		backward bool
+		want     string
+	}{
+		{false, "((rating < ?) OR (rating = ? AND id > ?))"},
+		{true, "((rating > ?) OR (rating = ? AND id < ?))"},
+	}
+	for _, tt := range tests {
+		condition, vars := keysetCondition(keys, values, tt.backward)
+		if condition != tt.want {
+			t.Errorf("keysetCondition(backward=%v) = %q, want %q", tt.backward, condition, tt.want)
+		}
+		if want := []interface{}{8.0, 8.0, int64(5)}; !reflect.DeepEqual(vars, want) {
+			t.Errorf("keysetCondition(backward=%v) vars = %v, want %v", tt.backward, vars, want)
+		}
+	}
+
+	if got := orderBySortKeys(keys, true); !reflect.DeepEqual(got, []string{"rating ASC", "id DESC"}) {
+		t.Errorf("orderBySortKeys(backward) = %v", got)
+	}
+}
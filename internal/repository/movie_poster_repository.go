@@ -3,14 +3,16 @@ package repository
 import (
 	"time"
 
+	"github.com/Cladkoewka/movie-manager/internal/constants"
 	"github.com/Cladkoewka/movie-manager/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type MoviePosterRepository interface {
-	SavePoster(movieID int64, poster []byte, mimeType string) error
+	SavePoster(movieID int64, poster []byte, mimeType string, audit AuditFunc) error
 	GetPosterByMovieID(movieID int64) (*model.MoviePoster, error)
-	DeletePoster(movieID int64) error
+	DeletePoster(movieID int64, audit AuditFunc) error
 }
 
 type MoviePosterRepositoryImpl struct {
@@ -21,14 +23,30 @@ func NewMoviePosterRepository(db *gorm.DB) *MoviePosterRepositoryImpl {
 	return &MoviePosterRepositoryImpl{db: db}
 }
 
-func (r *MoviePosterRepositoryImpl) SavePoster(movieID int64, poster []byte, mimeType string) error {
+// posterAuditRecord is what the audit log keeps of a poster: its type and
+// size, not the image itself
+type posterAuditRecord struct {
+	MimeType string `json:"mime_type"`
+	Size     int    `json:"size"`
+}
+
+func newPosterAuditRecord(poster model.MoviePoster) posterAuditRecord {
+	return posterAuditRecord{MimeType: poster.MimeType, Size: len(poster.Poster)}
+}
+
+func (r *MoviePosterRepositoryImpl) SavePoster(movieID int64, poster []byte, mimeType string, audit AuditFunc) error {
 	posterRecord := &model.MoviePoster{
 		Poster:   poster,
 		MovieID: movieID,
 		MimeType: mimeType,
 		CreatedAt: time.Now(),
 	}
-	return r.db.Create(posterRecord).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(posterRecord).Error; err != nil {
+			return err
+		}
+		return addAuditEntry(tx, audit, constants.AuditEntityPoster, movieID, constants.AuditActionCreate, nil, newPosterAuditRecord(*posterRecord))
+	})
 }
 
 func (r *MoviePosterRepositoryImpl) GetPosterByMovieID(movieID int64) (*model.MoviePoster, error) {
@@ -40,6 +58,17 @@ func (r *MoviePosterRepositoryImpl) GetPosterByMovieID(movieID int64) (*model.Mo
 	return &poster, nil
 }
 
-func (r *MoviePosterRepositoryImpl) DeletePoster(movieID int64) error {
-	return r.db.Where("movie_id = ?", movieID).Delete(&model.MoviePoster{}).Error
+func (r *MoviePosterRepositoryImpl) DeletePoster(movieID int64, audit AuditFunc) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var posters []model.MoviePoster
+		if err := tx.Clauses(clause.Returning{}).Where("movie_id = ?", movieID).Delete(&posters).Error; err != nil {
+			return err
+		}
+		for _, poster := range posters {
+			if err := addAuditEntry(tx, audit, constants.AuditEntityPoster, movieID, constants.AuditActionDelete, newPosterAuditRecord(poster), nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
@@ -1,33 +1,69 @@
 package repository
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/Cladkoewka/movie-manager/internal/cache"
 	"github.com/Cladkoewka/movie-manager/internal/config"
+	"github.com/Cladkoewka/movie-manager/internal/constants"
 	"github.com/Cladkoewka/movie-manager/internal/model"
 	"github.com/Cladkoewka/movie-manager/internal/model/dto"
 )
 
+const (
+	moviesCacheNamespace = "movies"
+	moviesCacheTTL       = 10 * time.Minute
+
+	headlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2"
+)
+
+// movieFacetExpressions maps facet names to the SQL expression movies are
+// grouped by. Rating buckets are whole points, so "7" counts movies rated
+// from 7.0 up to 7.9. The genre facet needs the join added in countFacets.
+var movieFacetExpressions = map[string]string{
+	"genre":    "fg.name",
+	"language": "language",
+	"decade":   "(EXTRACT(YEAR FROM release_date)::int / 10 * 10)::text",
+	"rating":   "floor(rating)::int::text",
+}
+
+// ErrVersionConflict means an update was based on an outdated version of the movie
+var ErrVersionConflict = errors.New("movie version conflict")
+
+// ErrMovieNotFound is returned by review writes whose movie is missing or in the trash
+var ErrMovieNotFound = errors.New("movie not found")
+
 type MovieRepository interface {
 	GetAllMovies(params dto.MovieQueryParams) (dto.MoviesResponse, error)
 	GetMovieByID(id int64) (*model.Movie, error)
-	CreateMovie(movie model.Movie) (*model.Movie, error)
-	UpdateMovie(movie model.Movie) (*model.Movie, error)
-	DeleteMovie(id int64) error
-	UpdateMovieTrailer(movieID int64, trailerURL string) error
+	CreateMovie(movie model.Movie, authorID *int64, author string, audit AuditFunc) (*model.Movie, error)
+	UpdateMovie(movie model.Movie, authorID *int64, author string, audit AuditFunc) (*model.Movie, error)
+	DeleteMovie(id int64, audit AuditFunc) error
+	UpdateMovieTrailer(movieID int64, trailerURL string, authorID *int64, author string, audit AuditFunc) error
+	GetDeletedMovies(page, pageSize int) ([]model.Movie, int64, error)
+	RestoreMovie(id int64, audit AuditFunc) (*model.Movie, error)
+	PurgeDeleted(before time.Time, audit AuditFunc) (int64, error)
+	GetMovieRevisions(movieID int64, page, pageSize int) ([]model.MovieRevision, int64, error)
+	GetMovieRevision(movieID int64, revision int) (*model.MovieRevision, error)
 }
 
 type MovieRepositoryImpl struct {
 	db *gorm.DB 
-	redisService *cache.RedisService 
+	cacheService cache.Cache
 }
 
-func NewMovieRepository(db *gorm.DB, redisService *cache.RedisService) MovieRepository {
-	return &MovieRepositoryImpl{db: db, redisService: redisService}
+func NewMovieRepository(db *gorm.DB, cacheService cache.Cache) MovieRepository {
+	return &MovieRepositoryImpl{db: db, cacheService: cacheService}
 }
 
 func NewDBConnection() (*gorm.DB, error) {
@@ -39,7 +75,7 @@ func NewDBConnection() (*gorm.DB, error) {
 	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
 		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName)
 
-		db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+		db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		return nil, err
 	}
@@ -48,102 +84,627 @@ func NewDBConnection() (*gorm.DB, error) {
 }
 
 func (r *MovieRepositoryImpl) GetAllMovies(params dto.MovieQueryParams) (dto.MoviesResponse, error) {
+	ctx := context.Background()
+	cacheKey, cacheErr := r.moviesCacheKey(ctx, params)
+	if cacheErr == nil {
+		var cached dto.MoviesResponse
+		if err := r.cacheService.GetCache(ctx, cacheKey, &cached); err == nil {
+			return cached, nil
+		}
+	}
+
+	response, err := r.searchMovies(params)
+	if err != nil {
+		return dto.MoviesResponse{}, err
+	}
+
+	// Nothing matched exactly, so retry with typo-tolerant title matching
+	if response.Total == 0 && params.Search != "" && !params.Fuzzy && params.Cursor == "" {
+		params.Fuzzy = true
+		response, err = r.searchMovies(params)
+		if err != nil {
+			return dto.MoviesResponse{}, err
+		}
+	}
+
+	if cacheErr == nil {
+		_ = r.cacheService.SetCache(ctx, cacheKey, response, moviesCacheTTL)
+	}
+
+	return response, nil
+}
+
+// searchMovies runs fuzzy searches in a transaction that sets the pg_trgm
+// similarity threshold, so that the `title % ?` filter in applyFilters
+// matches at the requested threshold and can use the trigram index
+func (r *MovieRepositoryImpl) searchMovies(params dto.MovieQueryParams) (dto.MoviesResponse, error) {
+	if !params.Fuzzy || params.Search == "" {
+		return r.findMovies(r.db, params)
+	}
+
+	var response dto.MoviesResponse
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		threshold := strconv.FormatFloat(params.FuzzyThreshold, 'f', -1, 64)
+		if err := tx.Exec("SELECT set_config('pg_trgm.similarity_threshold', ?, true)", threshold).Error; err != nil {
+			return err
+		}
+		var err error
+		response, err = r.findMovies(tx, params)
+		return err
+	})
+	return response, err
+}
+
+func (r *MovieRepositoryImpl) findMovies(db *gorm.DB, params dto.MovieQueryParams) (dto.MoviesResponse, error) {
 	var movies []model.Movie
 	var total int64
 
-	// Caching
-	// key, err := r.redisService.GenerateCacheKey("movies", params)
-	// if err == nil {
-	// 	if err := r.redisService.GetCache(context.Background(), key, &movies); err == nil {
-	// 		return dto.MoviesResponse{
-	// 			Movies: movies,
-	// 			Total:  int64(len(movies)), 
-	// 		}, nil
-	// 	}
-	// }
+	keys, keysetSupported := movieSortKeys(params)
+
+	var cursorValues []interface{}
+	var backward bool
+	if params.Cursor != "" {
+		if !keysetSupported {
+			return dto.MoviesResponse{}, ErrInvalidCursor
+		}
+		var err error
+		cursorValues, backward, err = decodeMovieCursor(params.Cursor, keys)
+		if err != nil {
+			return dto.MoviesResponse{}, err
+		}
+	}
 
-	query := r.db.Model(&model.Movie{})
+	query := r.applyFilters(db.Model(&model.Movie{}), params)
 
-	if params.Search != "" {
-		query = query.Where("title ILIKE ?", "%" + params.Search + "%")
+	if err := query.Count(&total).Error; err != nil {
+		return dto.MoviesResponse{}, err
+	}
+
+	if cursorValues != nil {
+		condition, vars := keysetCondition(keys, cursorValues, backward)
+		query = query.Where(condition, vars...)
+		query = query.Order(orderByExpr(orderBySortKeys(keys, backward), nil))
+		// One extra row tells whether another page follows in this direction
+		query = query.Limit(params.PageSize + 1)
+	} else {
+		query = r.applyOrder(query, params, keys)
+		offset := (params.Page - 1) * params.PageSize
+		query = query.Limit(params.PageSize).Offset(offset)
 	}
 
-	if params.Genre != "" {
-		query = query.Where("genre ILIKE ?", "%" + params.Genre + "%")
+	if err := query.Preload("Genres").Find(&movies).Error; err != nil {
+		return dto.MoviesResponse{}, err
 	}
 
-	if params.Language != "" {
-		query = query.Where("language ILIKE ?", "%" + params.Language + "%")
+	var hasMore bool
+	if cursorValues != nil {
+		hasMore = len(movies) > params.PageSize
+		if hasMore {
+			movies = movies[:params.PageSize]
+		}
+		if backward {
+			for i, j := 0, len(movies)-1; i < j; i, j = i+1, j-1 {
+				movies[i], movies[j] = movies[j], movies[i]
+			}
+		}
 	}
 
+	response := dto.MoviesResponse{
+		Movies: movies,
+		Total:  total,
+		Fuzzy:  params.Fuzzy,
+	}
+
+	if keysetSupported && len(movies) > 0 {
+		var hasNext, hasPrev bool
+		switch {
+		case cursorValues == nil:
+			hasNext = int64((params.Page-1)*params.PageSize+len(movies)) < total
+			hasPrev = params.Page > 1
+		case backward:
+			hasNext, hasPrev = true, hasMore
+		default:
+			hasNext, hasPrev = hasMore, true
+		}
+
+		var err error
+		if hasNext {
+			if response.NextCursor, err = encodeMovieCursor(keys, movies[len(movies)-1], false); err != nil {
+				return dto.MoviesResponse{}, err
+			}
+		}
+		if hasPrev {
+			if response.PrevCursor, err = encodeMovieCursor(keys, movies[0], true); err != nil {
+				return dto.MoviesResponse{}, err
+			}
+		}
+	}
+
+	if len(params.Facets) > 0 {
+		facets, err := r.countFacets(db, params)
+		if err != nil {
+			return dto.MoviesResponse{}, err
+		}
+		response.Facets = facets
+	}
+
+	if params.SearchMode == constants.SearchModeFullText && params.Search != "" && !params.Fuzzy {
+		highlights, err := r.findHighlights(movies, params.Search)
+		if err != nil {
+			return dto.MoviesResponse{}, err
+		}
+		response.Highlights = highlights
+	}
+
+	return response, nil
+}
+
+// applyFilters adds the WHERE conditions described by params to the query
+func (r *MovieRepositoryImpl) applyFilters(query *gorm.DB, params dto.MovieQueryParams) *gorm.DB {
+	if params.Search != "" {
+		if params.Fuzzy {
+			// The threshold is set for the transaction by searchMovies
+			query = query.Where("title % ?", params.Search)
+		} else if params.SearchMode == constants.SearchModeFullText {
+			query = query.Where("search_vector @@ websearch_to_tsquery('english', ?)", params.Search)
+		} else {
+			query = query.Where("title ILIKE ?", "%" + params.Search + "%")
+		}
+	}
+
+	if len(params.Genres) > 0 {
+		condition, args := textMatchCondition("g.name", params.Genres, params.Match)
+		query = query.Where(`EXISTS (
+			SELECT 1 FROM movie_genres mg JOIN genres g ON g.id = mg.genre_id
+			WHERE mg.movie_id = movies.id AND `+condition+`)`, args...)
+	}
+	query = applyTextFilter(query, "language", params.Languages, params.Match)
+	query = applyTextFilter(query, "director", params.Directors, params.Match)
+
 	if params.Rating != nil {
 		query = query.Where("rating >= ?", *params.Rating)
 	}
 
-	if err := query.Count(&total).Error; err != nil {
-		return dto.MoviesResponse{}, err
+	if params.MaxRating != nil {
+		query = query.Where("rating <= ?", *params.MaxRating)
 	}
 
-	query = query.Order(params.SortBy + " " + params.OrderBy)
+	if params.MinUserRating != nil {
+		query = query.Where("average_user_rating >= ?", *params.MinUserRating)
+	}
 
-	offset := (params.Page - 1) * params.PageSize
-	query = query.Limit(params.PageSize).Offset(offset)
+	if params.MaxUserRating != nil {
+		query = query.Where("average_user_rating <= ?", *params.MaxUserRating)
+	}
 
-	if err := query.Find(&movies).Error; err != nil {
-		return dto.MoviesResponse{}, err
+	if params.MinRatingCount != nil {
+		query = query.Where("rating_count >= ?", *params.MinRatingCount)
 	}
 
-	// if cacheKey, err := r.redisService.GenerateCacheKey("movies", params); err == nil {
-  //   _ = r.redisService.SetCache(context.Background(), cacheKey, movies, 10*time.Minute)
-	// }	
+	if params.ReleasedFrom != nil {
+		query = query.Where("release_date >= ?", *params.ReleasedFrom)
+	}
 
-	return dto.MoviesResponse{
-		Movies: movies,
-		Total:  total,
-	}, nil
+	// The upper bound is a whole day, so compare against the start of the next one
+	if params.ReleasedTo != nil {
+		query = query.Where("release_date < ?", params.ReleasedTo.AddDate(0, 0, 1))
+	}
+
+	if params.Year != nil {
+		yearStart := time.Date(*params.Year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		query = query.Where("release_date >= ? AND release_date < ?", yearStart, yearStart.AddDate(1, 0, 0))
+	}
+
+	if params.MinDuration != nil {
+		query = query.Where("duration >= ?", *params.MinDuration)
+	}
+
+	if params.MaxDuration != nil {
+		query = query.Where("duration <= ?", *params.MaxDuration)
+	}
+
+	return query
 }
 
+// countFacets groups the movies matching params by every requested facet
+func (r *MovieRepositoryImpl) countFacets(db *gorm.DB, params dto.MovieQueryParams) (map[string][]dto.FacetCount, error) {
+	facets := make(map[string][]dto.FacetCount, len(params.Facets))
+	for _, facet := range params.Facets {
+		expression, ok := movieFacetExpressions[facet]
+		if !ok {
+			continue
+		}
+
+		query := r.applyFilters(db.Model(&model.Movie{}), params)
+		if facet == "genre" {
+			query = query.Joins("JOIN movie_genres fmg ON fmg.movie_id = movies.id JOIN genres fg ON fg.id = fmg.genre_id")
+		}
+
+		counts := []dto.FacetCount{}
+		err := query.
+			Select(expression + " AS value, COUNT(*) AS count").
+			Where(expression + " IS NOT NULL").
+			Group("value").
+			Order("count DESC, value").
+			Scan(&counts).Error
+		if err != nil {
+			return nil, err
+		}
+		facets[facet] = counts
+	}
+	return facets, nil
+}
 
+// applyOrder sorts fuzzy matches by similarity first and full-text matches by rank
+func (r *MovieRepositoryImpl) applyOrder(query *gorm.DB, params dto.MovieQueryParams, keys []sortKey) *gorm.DB {
+	if keys != nil {
+		return query.Order(orderByExpr(orderBySortKeys(keys, false), nil))
+	}
+
+	var columns []string
+	var vars []interface{}
+
+	if params.Fuzzy {
+		columns = append(columns, "similarity(title, ?) DESC")
+		vars = append(vars, params.Search)
+	}
+
+	for _, field := range params.Sort {
+		switch {
+		case field.Field == constants.SortByRelevance:
+			if !params.Fuzzy {
+				columns = append(columns, "ts_rank(search_vector, websearch_to_tsquery('english', ?)) DESC")
+				vars = append(vars, params.Search)
+			}
+		case field.Desc:
+			columns = append(columns, field.Field+" DESC")
+		default:
+			columns = append(columns, field.Field+" ASC")
+		}
+	}
+	columns = append(columns, "id ASC")
+
+	return query.Order(orderByExpr(columns, vars))
+}
+
+func orderByExpr(columns []string, vars []interface{}) clause.OrderBy {
+	return clause.OrderBy{Expression: clause.Expr{
+		SQL:                strings.Join(columns, ", "),
+		Vars:               vars,
+		WithoutParentheses: true,
+	}}
+}
+
+// applyTextFilter keeps rows whose column matches any of the values
+func applyTextFilter(query *gorm.DB, column string, values []string, match string) *gorm.DB {
+	if len(values) == 0 {
+		return query
+	}
+	condition, args := textMatchCondition(column, values, match)
+	return query.Where(condition, args...)
+}
+
+// textMatchCondition matches the column against any of the values, either as
+// a case-insensitive substring or as a case-insensitive exact value
+func textMatchCondition(column string, values []string, match string) (string, []interface{}) {
+	if match == constants.MatchExact {
+		lowered := make([]string, len(values))
+		for i, value := range values {
+			lowered[i] = strings.ToLower(value)
+		}
+		return "lower(" + column + ") IN ?", []interface{}{lowered}
+	}
+
+	conditions := make([]string, len(values))
+	args := make([]interface{}, len(values))
+	for i, value := range values {
+		conditions[i] = column + " ILIKE ?"
+		args[i] = "%" + value + "%"
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")", args
+}
+
+// findHighlights returns the matched fragments of title and description for every movie
+func (r *MovieRepositoryImpl) findHighlights(movies []model.Movie, search string) ([]dto.MovieHighlight, error) {
+	if len(movies) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]int64, len(movies))
+	for i, movie := range movies {
+		ids[i] = movie.ID
+	}
+
+	var rows []dto.MovieHighlight
+	err := r.db.Model(&model.Movie{}).
+		Select(`id AS movie_id,
+			ts_headline('english', title, websearch_to_tsquery('english', ?), ?) AS title,
+			ts_headline('english', coalesce(description, ''), websearch_to_tsquery('english', ?), ?) AS snippet`,
+			search, headlineOptions, search, headlineOptions).
+		Where("id IN ?", ids).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int64]dto.MovieHighlight, len(rows))
+	for _, row := range rows {
+		byID[row.MovieID] = row
+	}
+
+	highlights := make([]dto.MovieHighlight, 0, len(movies))
+	for _, movie := range movies {
+		if highlight, ok := byID[movie.ID]; ok {
+			highlights = append(highlights, highlight)
+		}
+	}
+	return highlights, nil
+}
+
+// moviesCacheKey builds the cache key for a movie list page. The key includes
+// the current generation, so every write to movies makes old pages unreachable.
+func (r *MovieRepositoryImpl) moviesCacheKey(ctx context.Context, params dto.MovieQueryParams) (string, error) {
+	generation, err := r.cacheService.GetGeneration(ctx, moviesCacheNamespace)
+	if err != nil {
+		return "", err
+	}
+	return cache.GenerateCacheKey(fmt.Sprintf("%s:%d", moviesCacheNamespace, generation), params)
+}
+
+// invalidateMoviesCache drops all cached movie list pages
+func (r *MovieRepositoryImpl) invalidateMoviesCache() {
+	invalidateMoviesCache(r.cacheService)
+}
+
+// movieRatingColumns are maintained from reviews and never written by movie updates
+var movieRatingColumns = []string{"average_user_rating", "rating_count"}
+
+func invalidateMoviesCache(cacheService cache.Cache) {
+	if err := cacheService.BumpGeneration(context.Background(), moviesCacheNamespace); err != nil {
+		log.Printf("Failed to invalidate movies cache: %v", err)
+	}
+}
 
 func (r *MovieRepositoryImpl) GetMovieByID(id int64) (*model.Movie, error) {
 	var movie model.Movie
-	if err := r.db.First(&movie, id).Error; err != nil {
+	if err := r.db.Preload("Genres").First(&movie, id).Error; err != nil {
 		return nil, err
 	}
 	return &movie, nil
 }
 
-func (r *MovieRepositoryImpl) CreateMovie(movie model.Movie) (*model.Movie, error) {
-	if err := r.db.Create(&movie).Error; err != nil {
+// CreateMovie stores the movie and its first revision
+func (r *MovieRepositoryImpl) CreateMovie(movie model.Movie, authorID *int64, author string, audit AuditFunc) (*model.Movie, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		genres, err := resolveGenres(tx, movie.Genres)
+		if err != nil {
+			return err
+		}
+		movie.Genres = genres
+		movie.AverageUserRating, movie.RatingCount = 0, 0
+		movie.Version = 1
+		if err := tx.Omit("Genres.*").Create(&movie).Error; err != nil {
+			return err
+		}
+		if err := addMovieRevision(tx, movie, 0, authorID, author); err != nil {
+			return err
+		}
+		return addAuditEntry(tx, audit, constants.AuditEntityMovie, movie.ID, constants.AuditActionCreate, nil, movie)
+	})
+	if err != nil {
 		return nil, err
 	}
+	r.invalidateMoviesCache()
 	return &movie, nil
 }
 
-func (r *MovieRepositoryImpl) UpdateMovie(movie model.Movie) (*model.Movie, error) {
-	if err := r.db.Save(&movie).Error; err != nil {
+// UpdateMovie saves the movie and records the result as its next revision.
+// movie.Version must be the version the changes were based on; if the movie
+// has moved on since, nothing is saved and ErrVersionConflict is returned.
+func (r *MovieRepositoryImpl) UpdateMovie(movie model.Movie, authorID *int64, author string, audit AuditFunc) (*model.Movie, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Save would insert a missing row, and trashed movies must stay in the trash
+		current, latest, err := lockMovieForRevision(tx, movie.ID)
+		if err != nil {
+			return err
+		}
+		if current.Version != movie.Version {
+			return ErrVersionConflict
+		}
+		movie.Version = current.Version + 1
+
+		genres, err := resolveGenres(tx, movie.Genres)
+		if err != nil {
+			return err
+		}
+		omitted := append([]string{"created_at", "deleted_at", clause.Associations}, movieRatingColumns...)
+		if err := tx.Omit(omitted...).Save(&movie).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&movie).Association("Genres").Replace(genres); err != nil {
+			return err
+		}
+		if err := tx.Preload("Genres").First(&movie, movie.ID).Error; err != nil {
+			return err
+		}
+		if err := addMovieRevision(tx, movie, latest, authorID, author); err != nil {
+			return err
+		}
+		return addAuditEntry(tx, audit, constants.AuditEntityMovie, movie.ID, constants.AuditActionUpdate, current, movie)
+	})
+	if err != nil {
 		return nil, err
 	}
-	return &movie, nil	
+	r.invalidateMoviesCache()
+	return &movie, nil
 }
 
-func (r *MovieRepositoryImpl) DeleteMovie(id int64) error {
-	if err := r.db.Delete(&model.Movie{}, id).Error; err != nil {
+// DeleteMovie moves the movie to the trash together with its reviews and
+// posters. They share one deletion time, which is how RestoreMovie finds
+// them again.
+func (r *MovieRepositoryImpl) DeleteMovie(id int64, audit AuditFunc) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var movie model.Movie
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Genres").First(&movie, id).Error; err != nil {
+			return err
+		}
+		deletedAt := time.Now()
+		if err := tx.Model(&movie).UpdateColumn("deleted_at", deletedAt).Error; err != nil {
+			return err
+		}
+		for _, dependent := range []interface{}{&model.Review{}, &model.MoviePoster{}} {
+			err := tx.Model(dependent).Where("movie_id = ?", id).UpdateColumn("deleted_at", deletedAt).Error
+			if err != nil {
+				return err
+			}
+		}
+		return addAuditEntry(tx, audit, constants.AuditEntityMovie, id, constants.AuditActionDelete, movie, nil)
+	})
+	if err != nil {
 		return err
 	}
+	r.invalidateMoviesCache()
 	return nil
 }
 
-func (r *MovieRepositoryImpl) UpdateMovieTrailer(movieID int64, trailerURL string) error {
-	var movie model.Movie
-	if err := r.db.First(&movie, "id = ?", movieID).Error; err != nil {
-		return err
+// GetDeletedMovies lists the trash, most recently deleted first
+func (r *MovieRepositoryImpl) GetDeletedMovies(page, pageSize int) ([]model.Movie, int64, error) {
+	query := r.db.Unscoped().Model(&model.Movie{}).Where("deleted_at IS NOT NULL")
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
 	}
 
-	movie.TrailerURL = trailerURL
-	if err := r.db.Save(&movie).Error; err != nil {
-		return err
+	var movies []model.Movie
+	err := query.Preload("Genres").
+		Order("deleted_at DESC, id DESC").
+		Limit(pageSize).
+		Offset((page - 1) * pageSize).
+		Find(&movies).Error
+	return movies, total, err
+}
+
+// RestoreMovie takes a movie out of the trash with the reviews and posters
+// deleted along with it. Reviews deleted on their own before stay deleted.
+func (r *MovieRepositoryImpl) RestoreMovie(id int64, audit AuditFunc) (*model.Movie, error) {
+	var restored model.Movie
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var movie model.Movie
+		if err := tx.Unscoped().Where("deleted_at IS NOT NULL").First(&movie, id).Error; err != nil {
+			return err
+		}
+		for _, dependent := range []interface{}{&model.Review{}, &model.MoviePoster{}} {
+			err := tx.Unscoped().Model(dependent).
+				Where("movie_id = ? AND deleted_at = ?", id, movie.DeletedAt.Time).
+				UpdateColumn("deleted_at", nil).Error
+			if err != nil {
+				return err
+			}
+		}
+		if err := tx.Unscoped().Model(&movie).UpdateColumn("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		if err := updateMovieRating(tx, id); err != nil {
+			return err
+		}
+		if err := tx.Preload("Genres").First(&restored, id).Error; err != nil {
+			return err
+		}
+		return addAuditEntry(tx, audit, constants.AuditEntityMovie, id, constants.AuditActionRestore, nil, restored)
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.invalidateMoviesCache()
+	return &restored, nil
+}
+
+// PurgeDeleted permanently removes movies, reviews and posters deleted
+// before the cutoff, along with the reviews and posters of those movies, and
+// returns how many rows went
+func (r *MovieRepositoryImpl) PurgeDeleted(before time.Time, audit AuditFunc) (int64, error) {
+	var movies []model.Movie
+	var reviews []model.Review
+	var posters []model.MoviePoster
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Reviews and posters go first, those of purged movies included:
+		// deleting a movie would cascade to them unseen by the audit log
+		dependents := tx.Unscoped().Clauses(clause.Returning{}).
+			Where("deleted_at < ? OR movie_id IN (SELECT id FROM movies WHERE deleted_at < ?)", before, before).
+			Session(&gorm.Session{})
+		if err := dependents.Delete(&reviews).Error; err != nil {
+			return err
+		}
+		if err := dependents.Delete(&posters).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Clauses(clause.Returning{}).Where("deleted_at < ?", before).Delete(&movies).Error; err != nil {
+			return err
+		}
+
+		for _, movie := range movies {
+			if err := addAuditEntry(tx, audit, constants.AuditEntityMovie, movie.ID, constants.AuditActionPurge, movie, nil); err != nil {
+				return err
+			}
+		}
+		for _, review := range reviews {
+			if err := addAuditEntry(tx, audit, constants.AuditEntityReview, review.ID, constants.AuditActionPurge, review, nil); err != nil {
+				return err
+			}
+		}
+		for _, poster := range posters {
+			if err := addAuditEntry(tx, audit, constants.AuditEntityPoster, poster.MovieID, constants.AuditActionPurge, newPosterAuditRecord(poster), nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
 	}
+	return int64(len(movies) + len(reviews) + len(posters)), nil
+}
+
+// trailerAuditRecord is what the audit log keeps of a movie's trailer
+type trailerAuditRecord struct {
+	TrailerURL string `json:"trailer_url"`
+}
 
+// UpdateMovieTrailer points the movie at a new trailer. Like any other
+// update it raises the version and is recorded as a revision.
+func (r *MovieRepositoryImpl) UpdateMovieTrailer(movieID int64, trailerURL string, authorID *int64, author string, audit AuditFunc) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		current, latest, err := lockMovieForRevision(tx, movieID)
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&model.Movie{}).Where("id = ?", movieID).UpdateColumns(map[string]interface{}{
+			"trailer_url": trailerURL,
+			"version":     gorm.Expr("version + 1"),
+		}).Error
+		if err != nil {
+			return err
+		}
+		var movie model.Movie
+		if err := tx.Preload("Genres").First(&movie, movieID).Error; err != nil {
+			return err
+		}
+		if err := addMovieRevision(tx, movie, latest, authorID, author); err != nil {
+			return err
+		}
+
+		// A new trailer is logged as a creation and a replaced one as an update
+		after := trailerAuditRecord{TrailerURL: trailerURL}
+		if current.TrailerURL == "" {
+			return addAuditEntry(tx, audit, constants.AuditEntityTrailer, movieID, constants.AuditActionCreate, nil, after)
+		}
+		before := trailerAuditRecord{TrailerURL: current.TrailerURL}
+		return addAuditEntry(tx, audit, constants.AuditEntityTrailer, movieID, constants.AuditActionUpdate, before, after)
+	})
+	if err != nil {
+		return err
+	}
+	r.invalidateMoviesCache()
 	return nil
 }
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Cladkoewka/movie-manager/internal/cache"
+	"github.com/Cladkoewka/movie-manager/internal/model"
+)
+
+func TestPurgeDeletedAuditsEveryRow(t *testing.T) {
+	trashedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	db, fake := newFakeDB(t, func(query string) fakeResult {
+		switch {
+		case strings.HasPrefix(query, `DELETE FROM "reviews"`):
+			return fakeResult{
+				columns: []string{"id", "movie_id", "comment", "deleted_at"},
+				rows: [][]driver.Value{
+					{int64(1), int64(10), "Great", trashedAt},
+					{int64(2), int64(10), "Too long", trashedAt},
+				},
+			}
+		case strings.HasPrefix(query, `DELETE FROM "movie_posters"`):
+			return fakeResult{
+				columns: []string{"id", "movie_id", "poster", "mime_type", "deleted_at"},
+				rows:    [][]driver.Value{{int64(3), int64(10), []byte("png"), "image/png", trashedAt}},
+			}
+		case strings.HasPrefix(query, `DELETE FROM "movies"`):
+			return fakeResult{
+				columns: []string{"id", "title", "deleted_at"},
+				rows:    [][]driver.Value{{int64(10), "Heat", trashedAt}},
+			}
+		case strings.HasPrefix(query, `INSERT INTO "audit_entries"`):
+			return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}}
+		}
+		return fakeResult{}
+	})
+	repo := &MovieRepositoryImpl{db: db, cacheService: cache.NewNoopCache()}
+
+	var recorded []string
+	audit := func(entity string, entityID int64, action string, before, after interface{}) (*model.AuditEntry, error) {
+		if before == nil || after != nil {
+			t.Errorf("%s %d: before = %v, after = %v, want only before", entity, entityID, before, after)
+		}
+		recorded = append(recorded, fmt.Sprintf("%s %s %d", action, entity, entityID))
+		return &model.AuditEntry{Entity: entity, EntityID: entityID, Action: action}, nil
+	}
+
+	purged, err := repo.PurgeDeleted(trashedAt.Add(time.Hour), audit)
+	if err != nil {
+		t.Fatalf("PurgeDeleted: %v", err)
+	}
+	if purged != 4 {
+		t.Errorf("PurgeDeleted = %d, want 4", purged)
+	}
+
+	// One entry per row, the reviews and the poster of the movie included
+	want := []string{"purge movie 10", "purge review 1", "purge review 2", "purge poster 10"}
+	if !reflect.DeepEqual(recorded, want) {
+		t.Errorf("audited %v, want %v", recorded, want)
+	}
+	if inserts := fake.statementsWithPrefix(`INSERT INTO "audit_entries"`); len(inserts) != len(want) {
+		t.Errorf("%d audit entries inserted, want %d", len(inserts), len(want))
+	}
+
+	// The movie goes last, so the foreign keys have nothing left to cascade to
+	deletes := fake.statementsWithPrefix("DELETE")
+	if len(deletes) != 3 || !strings.HasPrefix(deletes[2], `DELETE FROM "movies"`) {
+		t.Fatalf("deletes = %q, want reviews and posters before movies", deletes)
+	}
+	for _, dependent := range deletes[:2] {
+		if !strings.Contains(dependent, "movie_id IN (SELECT id FROM movies WHERE deleted_at <") {
+			t.Errorf("%q does not take the rows of purged movies", dependent)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"github.com/Cladkoewka/movie-manager/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetMovieRevisions returns a page of the movie's revisions, newest first
+func (r *MovieRepositoryImpl) GetMovieRevisions(movieID int64, page, pageSize int) ([]model.MovieRevision, int64, error) {
+	query := r.db.Model(&model.MovieRevision{}).Where("movie_id = ?", movieID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	revisions := []model.MovieRevision{}
+	err := query.Order("revision DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&revisions).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return revisions, total, nil
+}
+
+func (r *MovieRepositoryImpl) GetMovieRevision(movieID int64, revision int) (*model.MovieRevision, error) {
+	var movieRevision model.MovieRevision
+	err := r.db.Where("movie_id = ? AND revision = ?", movieID, revision).First(&movieRevision).Error
+	if err != nil {
+		return nil, err
+	}
+	return &movieRevision, nil
+}
+
+func latestMovieRevision(tx *gorm.DB, movieID int64) (int, error) {
+	var latest int
+	err := tx.Model(&model.MovieRevision{}).
+		Select("COALESCE(MAX(revision), 0)").
+		Where("movie_id = ?", movieID).
+		Scan(&latest).Error
+	return latest, err
+}
+
+// lockMovieForRevision locks a movie that is about to be updated and returns
+// it, genres included, with its latest revision number. The lock keeps concurrent updates from
+// taking the same number. Movies created before revisions were kept get
+// their current state stored as a baseline first.
+func lockMovieForRevision(tx *gorm.DB, movieID int64) (model.Movie, int, error) {
+	var current model.Movie
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Genres").First(&current, movieID).Error; err != nil {
+		return model.Movie{}, 0, err
+	}
+	latest, err := latestMovieRevision(tx, movieID)
+	if err != nil {
+		return model.Movie{}, 0, err
+	}
+	if latest == 0 {
+		if err := addMovieRevision(tx, current, latest, nil, ""); err != nil {
+			return model.Movie{}, 0, err
+		}
+		latest++
+	}
+	return current, latest, nil
+}
+
+// addMovieRevision stores movie as the revision after latest. Callers either
+// just created the movie or hold its row lock, so numbers cannot collide.
+func addMovieRevision(tx *gorm.DB, movie model.Movie, latest int, authorID *int64, author string) error {
+	return tx.Create(&model.MovieRevision{
+		MovieID:  movie.ID,
+		Revision: latest + 1,
+		Snapshot: movie,
+		AuthorID: authorID,
+		Author:   author,
+	}).Error
+}
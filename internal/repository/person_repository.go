@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"github.com/Cladkoewka/movie-manager/internal/model"
+	"gorm.io/gorm"
+)
+
+type PersonRepository interface {
+	GetAll(search string) ([]model.Person, error)
+	GetByID(id int64) (*model.Person, error)
+	Create(person model.Person) (*model.Person, error)
+	Update(person model.Person) (*model.Person, error)
+	Delete(id int64) error
+	GetCreditsByMovieID(movieID int64) ([]model.MovieCredit, error)
+	GetCreditsByPersonID(personID int64) ([]model.MovieCredit, error)
+	CreateCredit(credit model.MovieCredit) (*model.MovieCredit, error)
+	DeleteCredit(movieID, creditID int64) error
+}
+
+type PersonRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewPersonRepository(db *gorm.DB) PersonRepository {
+	return &PersonRepositoryImpl{db: db}
+}
+
+func (r *PersonRepositoryImpl) GetAll(search string) ([]model.Person, error) {
+	var people []model.Person
+	query := r.db.Order("name, id")
+	if search != "" {
+		query = query.Where("name ILIKE ?", "%"+search+"%")
+	}
+	err := query.Find(&people).Error
+	return people, err
+}
+
+func (r *PersonRepositoryImpl) GetByID(id int64) (*model.Person, error) {
+	var person model.Person
+	if err := r.db.First(&person, id).Error; err != nil {
+		return nil, err
+	}
+	return &person, nil
+}
+
+func (r *PersonRepositoryImpl) Create(person model.Person) (*model.Person, error) {
+	if err := r.db.Create(&person).Error; err != nil {
+		return nil, err
+	}
+	return &person, nil
+}
+
+func (r *PersonRepositoryImpl) Update(person model.Person) (*model.Person, error) {
+	result := r.db.Model(&model.Person{}).Where("id = ?", person.ID).
+		Select("name", "birth_date", "biography").
+		Updates(&person)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.GetByID(person.ID)
+}
+
+func (r *PersonRepositoryImpl) Delete(id int64) error {
+	result := r.db.Delete(&model.Person{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *PersonRepositoryImpl) GetCreditsByMovieID(movieID int64) ([]model.MovieCredit, error) {
+	var credits []model.MovieCredit
+	err := r.db.Preload("Person").
+		Where("movie_id = ?", movieID).
+		Order("billing_order, id").
+		Find(&credits).Error
+	return credits, err
+}
+
+func (r *PersonRepositoryImpl) GetCreditsByPersonID(personID int64) ([]model.MovieCredit, error) {
+	var credits []model.MovieCredit
+	err := r.db.Preload("Movie.Genres").
+		Joins("JOIN movies ON movies.id = movie_credits.movie_id AND movies.deleted_at IS NULL").
+		Where("movie_credits.person_id = ?", personID).
+		Order("movies.release_date DESC, movie_credits.id").
+		Find(&credits).Error
+	return credits, err
+}
+
+func (r *PersonRepositoryImpl) CreateCredit(credit model.MovieCredit) (*model.MovieCredit, error) {
+	if err := r.db.Omit("Movie", "Person").Create(&credit).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Preload("Person").First(&credit, credit.ID).Error; err != nil {
+		return nil, err
+	}
+	return &credit, nil
+}
+
+func (r *PersonRepositoryImpl) DeleteCredit(movieID, creditID int64) error {
+	result := r.db.Where("movie_id = ?", movieID).Delete(&model.MovieCredit{}, creditID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+
+	"github.com/Cladkoewka/movie-manager/internal/constants"
+	"github.com/Cladkoewka/movie-manager/internal/model"
+)
+
+// Unscored reviews sort after scored ones in both directions
+const (
+	reviewScoreDesc = "COALESCE(score, 0)"
+	reviewScoreAsc  = "COALESCE(score, 11)"
+)
+
+// reviewHelpfulness ranks reviews by net helpful votes
+const reviewHelpfulness = "helpful_count - unhelpful_count"
+
+// reviewSortKeys maps each review ordering to its keyset columns, ending with id
+var reviewSortKeys = map[string][]sortKey{
+	constants.ReviewSortNewest:  {{Column: "created_at", Desc: true}, {Column: "id", Desc: true}},
+	constants.ReviewSortOldest:  {{Column: "created_at"}, {Column: "id"}},
+	constants.ReviewSortHighest: {{Column: reviewScoreDesc, Desc: true}, {Column: "created_at", Desc: true}, {Column: "id", Desc: true}},
+	constants.ReviewSortLowest:  {{Column: reviewScoreAsc}, {Column: "created_at", Desc: true}, {Column: "id", Desc: true}},
+	constants.ReviewSortHelpful: {{Column: reviewHelpfulness, Desc: true}, {Column: "created_at", Desc: true}, {Column: "id", Desc: true}},
+}
+
+// reviewSortValues reads the value of a sort column from a loaded review
+var reviewSortValues = map[string]func(model.Review) interface{}{
+	"id":         func(r model.Review) interface{} { return r.ID },
+	"created_at": func(r model.Review) interface{} { return r.CreatedAt },
+	reviewScoreDesc: func(r model.Review) interface{} {
+		if r.Score == nil {
+			return 0
+		}
+		return *r.Score
+	},
+	reviewScoreAsc: func(r model.Review) interface{} {
+		if r.Score == nil {
+			return constants.MaxReviewScore + 1
+		}
+		return *r.Score
+	},
+	reviewHelpfulness: func(r model.Review) interface{} { return r.HelpfulCount - r.UnhelpfulCount },
+}
+
+// reviewCursor has the same shape as movieCursor, keyed by the review sort name
+type reviewCursor struct {
+	Sort     string            `json:"s"`
+	Values   []json.RawMessage `json:"v"`
+	Backward bool              `json:"b,omitempty"`
+}
+
+func encodeReviewCursor(sort string, review model.Review, backward bool) (string, error) {
+	cursor := reviewCursor{Sort: sort, Backward: backward}
+	for _, key := range reviewSortKeys[sort] {
+		value, err := json.Marshal(reviewSortValues[key.Column](review))
+		if err != nil {
+			return "", err
+		}
+		cursor.Values = append(cursor.Values, value)
+	}
+
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeReviewCursor(encoded string, sort string) (values []interface{}, backward bool, err error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, false, ErrInvalidCursor
+	}
+
+	var cursor reviewCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, false, ErrInvalidCursor
+	}
+	keys := reviewSortKeys[sort]
+	if cursor.Sort != sort || len(cursor.Values) != len(keys) {
+		return nil, false, ErrInvalidCursor
+	}
+
+	for i, key := range keys {
+		target := reflect.New(reflect.TypeOf(reviewSortValues[key.Column](model.Review{})))
+		if err := json.Unmarshal(cursor.Values[i], target.Interface()); err != nil {
+			return nil, false, ErrInvalidCursor
+		}
+		values = append(values, target.Elem().Interface())
+	}
+	return values, cursor.Backward, nil
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Cladkoewka/movie-manager/internal/constants"
+	"github.com/Cladkoewka/movie-manager/internal/model"
+)
+
+func TestReviewCursorRoundTrip(t *testing.T) {
+	created := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	unscored := model.Review{ID: 7, CreatedAt: created}
+
+	values, backward, err := decodeReviewCursor(mustEncodeReviewCursor(t, constants.ReviewSortLowest, unscored), constants.ReviewSortLowest)
+	if err != nil {
+		t.Fatalf("decodeReviewCursor: %v", err)
+	}
+	// Unscored reviews sort last, so their cursor carries the score past the maximum
+	want := []interface{}{constants.MaxReviewScore + 1, created, int64(7)}
+	if !reflect.DeepEqual(values, want) || backward {
+		t.Errorf("decodeReviewCursor = %#v, %v, want %#v, false", values, backward, want)
+	}
+
+	encoded := mustEncodeReviewCursor(t, constants.ReviewSortNewest, unscored)
+	if _, _, err := decodeReviewCursor(encoded, constants.ReviewSortOldest); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("cursor for another sort: error = %v, want ErrInvalidCursor", err)
+	}
+}
+
+func mustEncodeReviewCursor(t *testing.T, sort string, review model.Review) string {
+	t.Helper()
+	encoded, err := encodeReviewCursor(sort, review, false)
+	if err != nil {
+		t.Fatalf("encodeReviewCursor(%s): %v", sort, err)
+	}
+	return encoded
+}
@@ -1,37 +1,480 @@
 package repository
 
 import (
-	"gorm.io/gorm"
+	"fmt"
+	"time"
+
+	"github.com/Cladkoewka/movie-manager/internal/cache"
+	"github.com/Cladkoewka/movie-manager/internal/constants"
 	"github.com/Cladkoewka/movie-manager/internal/model"
+	"github.com/Cladkoewka/movie-manager/internal/model/dto"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ReviewRepository interface {
-	GetAllByMovieID(movieID int64) ([]model.Review, error)
-	Create(review model.Review) (*model.Review, error)
-	Delete(reviewID int64) error
+	GetByMovieID(movieID int64, params dto.ReviewQueryParams) (dto.ReviewsResponse, error)
+	GetByID(reviewID int64) (*model.Review, error)
+	Create(review model.Review, audit AuditFunc) (*model.Review, error)
+	Update(review model.Review, audit AuditFunc) (*model.Review, error)
+	Delete(reviewID int64, audit AuditFunc) error
+	GetThread(rootID int64) ([]model.Review, error)
+	GetByStatus(status string, page, pageSize int) ([]model.Review, int64, error)
+	SetStatus(reviewID int64, status, reason string, moderatorID int64, audit AuditFunc) (*model.Review, error)
+	CountByUserSince(userID int64, since time.Time) (int64, error)
+	HasDuplicateComment(userID int64, comment string, excludeReviewID int64) (bool, error)
+	Vote(vote model.ReviewVote) (*model.Review, error)
+	Report(report model.ReviewReport, threshold int, audit AuditFunc) (*model.Review, error)
 }
 
 type ReviewRepositoryImpl struct {
-	db *gorm.DB
+	db           *gorm.DB
+	cacheService cache.Cache
 }
 
-func NewReviewRepository(db *gorm.DB) ReviewRepository {
-	return &ReviewRepositoryImpl{db: db}
+func NewReviewRepository(db *gorm.DB, cacheService cache.Cache) ReviewRepository {
+	return &ReviewRepositoryImpl{db: db, cacheService: cacheService}
 }
 
-func (r *ReviewRepositoryImpl) GetAllByMovieID(movieID int64) ([]model.Review, error) {
+// GetByMovieID returns one page of a movie's approved top-level reviews in the order
+// given by params.Sort, together with the total count and the score histogram
+func (r *ReviewRepositoryImpl) GetByMovieID(movieID int64, params dto.ReviewQueryParams) (dto.ReviewsResponse, error) {
+	keys := reviewSortKeys[params.Sort]
+
+	var cursorValues []interface{}
+	var backward bool
+	if params.Cursor != "" {
+		var err error
+		cursorValues, backward, err = decodeReviewCursor(params.Cursor, params.Sort)
+		if err != nil {
+			return dto.ReviewsResponse{}, err
+		}
+	}
+
+	var total int64
+	if err := r.approvedReviews(movieID).Count(&total).Error; err != nil {
+		return dto.ReviewsResponse{}, err
+	}
+
+	query := r.approvedReviews(movieID)
+	if cursorValues != nil {
+		condition, vars := keysetCondition(keys, cursorValues, backward)
+		query = query.Where(condition, vars...).
+			Order(orderByExpr(orderBySortKeys(keys, backward), nil)).
+			Limit(params.PageSize + 1)
+	} else {
+		query = query.Order(orderByExpr(orderBySortKeys(keys, false), nil)).
+			Limit(params.PageSize).
+			Offset((params.Page - 1) * params.PageSize)
+	}
+
 	var reviews []model.Review
-	err := r.db.Where("movie_id = ?", movieID).Find(&reviews).Error
-	return reviews, err
+	if err := query.Find(&reviews).Error; err != nil {
+		return dto.ReviewsResponse{}, err
+	}
+
+	var hasMore bool
+	if cursorValues != nil {
+		hasMore = len(reviews) > params.PageSize
+		if hasMore {
+			reviews = reviews[:params.PageSize]
+		}
+		if backward {
+			for i, j := 0, len(reviews)-1; i < j; i, j = i+1, j-1 {
+				reviews[i], reviews[j] = reviews[j], reviews[i]
+			}
+		}
+	}
+
+	response := dto.ReviewsResponse{Reviews: reviews, Total: total}
+
+	if len(reviews) > 0 {
+		var hasNext, hasPrev bool
+		switch {
+		case cursorValues == nil:
+			hasNext = int64((params.Page-1)*params.PageSize+len(reviews)) < total
+			hasPrev = params.Page > 1
+		case backward:
+			hasNext, hasPrev = true, hasMore
+		default:
+			hasNext, hasPrev = hasMore, true
+		}
+
+		var err error
+		if hasNext {
+			if response.NextCursor, err = encodeReviewCursor(params.Sort, reviews[len(reviews)-1], false); err != nil {
+				return dto.ReviewsResponse{}, err
+			}
+		}
+		if hasPrev {
+			if response.PrevCursor, err = encodeReviewCursor(params.Sort, reviews[0], true); err != nil {
+				return dto.ReviewsResponse{}, err
+			}
+		}
+	}
+
+	histogram, average, err := r.scoreHistogram(movieID)
+	if err != nil {
+		return dto.ReviewsResponse{}, err
+	}
+	response.ScoreHistogram = histogram
+	response.AverageScore = average
+
+	return response, nil
+}
+
+// scoreHistogram counts the movie's reviews per score, including empty buckets
+func (r *ReviewRepositoryImpl) scoreHistogram(movieID int64) ([]dto.ScoreCount, float64, error) {
+	var rows []dto.ScoreCount
+	err := r.approvedReviews(movieID).
+		Select("score, COUNT(*) AS count").
+		Where("score IS NOT NULL").
+		Group("score").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	histogram := make([]dto.ScoreCount, 0, constants.MaxReviewScore-constants.MinReviewScore+1)
+	for score := constants.MinReviewScore; score <= constants.MaxReviewScore; score++ {
+		histogram = append(histogram, dto.ScoreCount{Score: score})
+	}
+
+	var sum, count int64
+	for _, row := range rows {
+		histogram[row.Score-constants.MinReviewScore].Count = row.Count
+		sum += int64(row.Score) * row.Count
+		count += row.Count
+	}
+
+	var average float64
+	if count > 0 {
+		average = float64(sum) / float64(count)
+	}
+	return histogram, average, nil
 }
 
-func (r *ReviewRepositoryImpl) Create(review model.Review) (*model.Review, error) {
-	if err := r.db.Create(&review).Error; err != nil {
+// approvedReviews selects the movie's published reviews, leaving out replies
+func (r *ReviewRepositoryImpl) approvedReviews(movieID int64) *gorm.DB {
+	return r.db.Model(&model.Review{}).
+		Where("movie_id = ? AND status = ? AND parent_id IS NULL", movieID, constants.ReviewStatusApproved)
+}
+
+func (r *ReviewRepositoryImpl) GetByID(reviewID int64) (*model.Review, error) {
+	var review model.Review
+	if err := r.db.First(&review, reviewID).Error; err != nil {
 		return nil, err
 	}
 	return &review, nil
 }
 
-func (r *ReviewRepositoryImpl) Delete(reviewID int64) error {
-	return r.db.Delete(&model.Review{}, reviewID).Error
+func (r *ReviewRepositoryImpl) Create(review model.Review, audit AuditFunc) (*model.Review, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockMovie(tx, review.MovieID); err != nil {
+			return err
+		}
+		if err := tx.Create(&review).Error; err != nil {
+			return err
+		}
+		if err := updateMovieRating(tx, review.MovieID); err != nil {
+			return err
+		}
+		return addAuditEntry(tx, audit, constants.AuditEntityReview, review.ID, constants.AuditActionCreate, nil, review)
+	})
+	if err != nil {
+		return nil, err
+	}
+	invalidateMoviesCache(r.cacheService)
+	return &review, nil
+}
+
+// Update saves the editable fields; the author, movie and creation time are kept
+func (r *ReviewRepositoryImpl) Update(review model.Review, audit AuditFunc) (*model.Review, error) {
+	var updated model.Review
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockMovie(tx, review.MovieID); err != nil {
+			return err
+		}
+		var before model.Review
+		if err := tx.First(&before, review.ID).Error; err != nil {
+			return err
+		}
+		err := tx.Model(&model.Review{ID: review.ID}).Select("comment", "score", "status", "moderation_reason", "updated_at").Updates(&review).Error
+		if err != nil {
+			return err
+		}
+		if err := updateMovieRating(tx, review.MovieID); err != nil {
+			return err
+		}
+		if err := tx.First(&updated, review.ID).Error; err != nil {
+			return err
+		}
+		return addAuditEntry(tx, audit, constants.AuditEntityReview, review.ID, constants.AuditActionUpdate, before, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	invalidateMoviesCache(r.cacheService)
+	return &updated, nil
+}
+
+// Delete removes a review. A review with replies becomes a tombstone so the
+// thread stays intact; a tombstone left without replies is removed as well.
+func (r *ReviewRepositoryImpl) Delete(reviewID int64, audit AuditFunc) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var review model.Review
+		if err := tx.First(&review, reviewID).Error; err != nil {
+			return err
+		}
+		if err := lockMovie(tx, review.MovieID); err != nil {
+			return err
+		}
+
+		hasReplies, err := hasReplies(tx, review.ID)
+		if err != nil {
+			return err
+		}
+		if hasReplies {
+			err := tx.Model(&review).Updates(map[string]interface{}{
+				"comment": constants.DeletedReviewPlaceholder,
+				"score":   nil,
+				"user_id": nil,
+				"deleted": true,
+			}).Error
+			if err != nil {
+				return err
+			}
+		} else {
+			if err := tx.Delete(&review).Error; err != nil {
+				return err
+			}
+			if err := removeOrphanTombstones(tx, review.ParentID); err != nil {
+				return err
+			}
+		}
+		if err := updateMovieRating(tx, review.MovieID); err != nil {
+			return err
+		}
+		return addAuditEntry(tx, audit, constants.AuditEntityReview, review.ID, constants.AuditActionDelete, review, nil)
+	})
+	if err != nil {
+		return err
+	}
+	invalidateMoviesCache(r.cacheService)
+	return nil
+}
+
+func hasReplies(tx *gorm.DB, reviewID int64) (bool, error) {
+	var count int64
+	err := tx.Model(&model.Review{}).Where("parent_id = ?", reviewID).Count(&count).Error
+	return count > 0, err
+}
+
+// removeOrphanTombstones walks up from parentID deleting tombstones whose
+// last reply is gone
+func removeOrphanTombstones(tx *gorm.DB, parentID *int64) error {
+	for parentID != nil {
+		var parent model.Review
+		if err := tx.First(&parent, *parentID).Error; err != nil {
+			return err
+		}
+		if !parent.Deleted {
+			return nil
+		}
+		replies, err := hasReplies(tx, parent.ID)
+		if err != nil || replies {
+			return err
+		}
+		if err := tx.Delete(&parent).Error; err != nil {
+			return err
+		}
+		parentID = parent.ParentID
+	}
+	return nil
+}
+
+// GetThread returns the review and all replies below it, in no particular order
+func (r *ReviewRepositoryImpl) GetThread(rootID int64) ([]model.Review, error) {
+	var reviews []model.Review
+	err := r.db.Raw(`WITH RECURSIVE thread AS (
+			SELECT * FROM reviews WHERE id = ? AND deleted_at IS NULL
+			UNION ALL
+			SELECT reviews.* FROM reviews JOIN thread ON reviews.parent_id = thread.id
+			WHERE reviews.deleted_at IS NULL
+		)
+		SELECT * FROM thread`, rootID).Scan(&reviews).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(reviews) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return reviews, nil
+}
+
+// lockMovie serializes review writes per movie, so each recomputation sees
+// the reviews committed by the writers before it. A movie in the trash takes
+// no new reviews, and trashing one takes the same lock.
+func lockMovie(tx *gorm.DB, movieID int64) error {
+	result := tx.Exec(`SELECT 1 FROM movies WHERE id = ? AND deleted_at IS NULL FOR UPDATE`, movieID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrMovieNotFound
+	}
+	return nil
+}
+
+func updateMovieRating(tx *gorm.DB, movieID int64) error {
+	return tx.Exec(updateMovieRatingsSQL+` WHERE movies.id = ?`, movieID).Error
+}
+
+// GetByStatus returns a page of reviews in one moderation state, oldest first
+func (r *ReviewRepositoryImpl) GetByStatus(status string, page, pageSize int) ([]model.Review, int64, error) {
+	query := r.db.Model(&model.Review{}).Where("status = ?", status)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var reviews []model.Review
+	err := query.Order("created_at ASC, id ASC").
+		Limit(pageSize).
+		Offset((page - 1) * pageSize).
+		Find(&reviews).Error
+	return reviews, total, err
+}
+
+// SetStatus records a moderator's decision and refreshes the movie rating,
+// since only approved reviews count towards it
+func (r *ReviewRepositoryImpl) SetStatus(reviewID int64, status, reason string, moderatorID int64, audit AuditFunc) (*model.Review, error) {
+	var updated model.Review
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var review model.Review
+		if err := tx.First(&review, reviewID).Error; err != nil {
+			return err
+		}
+		if err := lockMovie(tx, review.MovieID); err != nil {
+			return err
+		}
+		err := tx.Model(&model.Review{ID: review.ID}).Updates(map[string]interface{}{
+			"status":            status,
+			"moderation_reason": reason,
+			"moderated_by":      moderatorID,
+			"moderated_at":      time.Now(),
+		}).Error
+		if err != nil {
+			return err
+		}
+		if err := updateMovieRating(tx, review.MovieID); err != nil {
+			return err
+		}
+		if err := tx.First(&updated, reviewID).Error; err != nil {
+			return err
+		}
+		return addAuditEntry(tx, audit, constants.AuditEntityReview, reviewID, constants.AuditActionUpdate, review, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	invalidateMoviesCache(r.cacheService)
+	return &updated, nil
+}
+
+func (r *ReviewRepositoryImpl) CountByUserSince(userID int64, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Review{}).
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Count(&count).Error
+	return count, err
+}
+
+// HasDuplicateComment reports whether the user already posted the comment,
+// ignoring case and surrounding whitespace
+func (r *ReviewRepositoryImpl) HasDuplicateComment(userID int64, comment string, excludeReviewID int64) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Review{}).
+		Where("user_id = ? AND id <> ? AND lower(trim(comment)) = lower(trim(?))", userID, excludeReviewID, comment).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Vote records or replaces the user's vote and refreshes the review's counters
+func (r *ReviewRepositoryImpl) Vote(vote model.ReviewVote) (*model.Review, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var review model.Review
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&review, vote.ReviewID).Error; err != nil {
+			return err
+		}
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "review_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"helpful", "updated_at"}),
+		}).Create(&vote).Error
+		if err != nil {
+			return err
+		}
+		return tx.Exec(`UPDATE reviews SET
+			helpful_count = (SELECT COUNT(*) FROM review_votes WHERE review_id = reviews.id AND helpful),
+			unhelpful_count = (SELECT COUNT(*) FROM review_votes WHERE review_id = reviews.id AND NOT helpful)
+			WHERE id = ?`, vote.ReviewID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByID(vote.ReviewID)
+}
+
+// Report stores the report and sends an approved review back to the
+// moderation queue once threshold users have reported it. A second report
+// by the same user fails with gorm.ErrDuplicatedKey. Only the requeue is
+// audited, not the report itself.
+func (r *ReviewRepositoryImpl) Report(report model.ReviewReport, threshold int, audit AuditFunc) (*model.Review, error) {
+	var requeued bool
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var review model.Review
+		if err := tx.First(&review, report.ReviewID).Error; err != nil {
+			return err
+		}
+		// Lock in the same order as review writes: the movie, then the review
+		if err := lockMovie(tx, review.MovieID); err != nil {
+			return err
+		}
+		if err := tx.Create(&report).Error; err != nil {
+			return err
+		}
+
+		var reportCount int64
+		if err := tx.Model(&model.ReviewReport{}).Where("review_id = ?", review.ID).Count(&reportCount).Error; err != nil {
+			return err
+		}
+		updates := map[string]interface{}{"report_count": reportCount}
+		if reportCount >= int64(threshold) && review.Status == constants.ReviewStatusApproved {
+			updates["status"] = constants.ReviewStatusPending
+			updates["moderation_reason"] = fmt.Sprintf("reported by %d users", reportCount)
+			requeued = true
+		}
+		if err := tx.Model(&model.Review{ID: review.ID}).UpdateColumns(updates).Error; err != nil {
+			return err
+		}
+		if !requeued {
+			return nil
+		}
+		if err := updateMovieRating(tx, review.MovieID); err != nil {
+			return err
+		}
+		var updated model.Review
+		if err := tx.First(&updated, review.ID).Error; err != nil {
+			return err
+		}
+		return addAuditEntry(tx, audit, constants.AuditEntityReview, review.ID, constants.AuditActionUpdate, review, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if requeued {
+		invalidateMoviesCache(r.cacheService)
+	}
+	return r.GetByID(report.ReviewID)
 }
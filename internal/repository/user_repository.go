@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"github.com/Cladkoewka/movie-manager/internal/model"
+	"gorm.io/gorm"
+)
+
+type UserRepository interface {
+	Create(user model.User) (*model.User, error)
+	GetByID(id int64) (*model.User, error)
+	GetByUsername(username string) (*model.User, error)
+	GetAll() ([]model.User, error)
+	UpdateRole(id int64, role string) (*model.User, error)
+}
+
+type UserRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &UserRepositoryImpl{db: db}
+}
+
+func (r *UserRepositoryImpl) Create(user model.User) (*model.User, error) {
+	if err := r.db.Create(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepositoryImpl) GetByID(id int64) (*model.User, error) {
+	var user model.User
+	if err := r.db.First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepositoryImpl) GetByUsername(username string) (*model.User, error) {
+	var user model.User
+	if err := r.db.Where("username = ?", username).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepositoryImpl) GetAll() ([]model.User, error) {
+	var users []model.User
+	err := r.db.Order("id").Find(&users).Error
+	return users, err
+}
+
+func (r *UserRepositoryImpl) UpdateRole(id int64, role string) (*model.User, error) {
+	result := r.db.Model(&model.User{}).Where("id = ?", id).Update("role", role)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.GetByID(id)
+}
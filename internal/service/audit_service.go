@@ -0,0 +1,68 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/Cladkoewka/movie-manager/internal/auth"
+	"github.com/Cladkoewka/movie-manager/internal/constants"
+	"github.com/Cladkoewka/movie-manager/internal/diff"
+	"github.com/Cladkoewka/movie-manager/internal/model"
+	"github.com/Cladkoewka/movie-manager/internal/model/dto"
+	"github.com/Cladkoewka/movie-manager/internal/repository"
+)
+
+// AuditService keeps the log of catalog changes made through the other services
+type AuditService struct {
+	repo repository.AuditRepository
+}
+
+func NewAuditService(repo repository.AuditRepository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// For returns the AuditFunc that records changes made by actor
+func (s *AuditService) For(actor auth.Principal) repository.AuditFunc {
+	return func(entity string, entityID int64, action string, before, after interface{}) (*model.AuditEntry, error) {
+		changes, err := diff.Compare(before, after)
+		if err != nil {
+			return nil, fmt.Errorf("diff %s %d for the audit log: %w", entity, entityID, err)
+		}
+		return &model.AuditEntry{
+			ActorID:  actorID(actor),
+			Actor:    actor.Username,
+			Entity:   entity,
+			EntityID: entityID,
+			Action:   action,
+			Diff:     changes,
+		}, nil
+	}
+}
+
+func (s *AuditService) GetEntries(params dto.AuditQueryParams) (dto.AuditLogResponse, error) {
+	if params.Entity != "" && !constants.AllowedAuditEntities[params.Entity] {
+		return dto.AuditLogResponse{}, NewValidationError("entity", fmt.Sprintf("unknown entity %q", params.Entity))
+	}
+	if params.From != nil && params.To != nil && params.From.After(*params.To) {
+		return dto.AuditLogResponse{}, NewValidationError("to", "must not be before from")
+	}
+	if params.Page <= 0 {
+		params.Page = constants.DefaultPage
+	}
+	if params.PageSize <= 0 {
+		params.PageSize = constants.DefaultAuditPageSize
+	}
+
+	entries, total, err := s.repo.Find(params)
+	if err != nil {
+		return dto.AuditLogResponse{}, err
+	}
+	return dto.AuditLogResponse{Entries: entries, Total: total}, nil
+}
+
+// actorID is the user behind a change, or nil when the server made it on its own
+func actorID(actor auth.Principal) *int64 {
+	if actor.UserID == 0 {
+		return nil
+	}
+	return &actor.UserID
+}
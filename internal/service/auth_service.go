@@ -0,0 +1,132 @@
+package service
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+
+	"github.com/Cladkoewka/movie-manager/internal/auth"
+	"github.com/Cladkoewka/movie-manager/internal/model"
+	"github.com/Cladkoewka/movie-manager/internal/model/dto"
+	"github.com/Cladkoewka/movie-manager/internal/repository"
+	"gorm.io/gorm"
+)
+
+const (
+	minUsernameLength = 3
+	maxUsernameLength = 32
+	minPasswordLength = 8
+	// bcrypt ignores everything after 72 bytes
+	maxPasswordLength = 72
+)
+
+type AuthService struct {
+	users  repository.UserRepository
+	tokens *auth.TokenManager
+}
+
+func NewAuthService(users repository.UserRepository, tokens *auth.TokenManager) *AuthService {
+	return &AuthService{users: users, tokens: tokens}
+}
+
+func (s *AuthService) Register(request dto.RegisterRequest) (*model.User, error) {
+	request.Username = strings.TrimSpace(request.Username)
+	request.Email = strings.TrimSpace(request.Email)
+
+	if len(request.Username) < minUsernameLength || len(request.Username) > maxUsernameLength {
+		return nil, NewValidationError("username", "must be between 3 and 32 characters")
+	}
+	if _, err := mail.ParseAddress(request.Email); err != nil {
+		return nil, NewValidationError("email", "must be a valid email address")
+	}
+	if len(request.Password) < minPasswordLength || len(request.Password) > maxPasswordLength {
+		return nil, NewValidationError("password", "must be between 8 and 72 characters")
+	}
+
+	hash, err := auth.HashPassword(request.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.users.Create(model.User{
+		Username:     request.Username,
+		Email:        strings.ToLower(request.Email),
+		PasswordHash: hash,
+		Role:         auth.RoleUser,
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, ErrUserExists
+	}
+	return user, err
+}
+
+func (s *AuthService) Login(request dto.LoginRequest) (*dto.AuthResponse, error) {
+	user, err := s.users.GetByUsername(strings.TrimSpace(request.Username))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !auth.CheckPassword(user.PasswordHash, request.Password) {
+		return nil, ErrInvalidCredentials
+	}
+	return s.issueTokens(user)
+}
+
+// Refresh exchanges a valid refresh token for a new token pair.
+// The user is reloaded, so role changes take effect and deleted
+// accounts cannot keep refreshing.
+func (s *AuthService) Refresh(request dto.RefreshRequest) (*dto.AuthResponse, error) {
+	principal, err := s.tokens.ParseRefreshToken(request.RefreshToken)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	user, err := s.users.GetByID(principal.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.issueTokens(user)
+}
+
+// Authenticate verifies an access token and returns its caller with the
+// role the user has now, so a revoked role stops working at once rather than
+// when the token expires. A token of a deleted user is invalid.
+func (s *AuthService) Authenticate(token string) (auth.Principal, error) {
+	principal, err := s.tokens.ParseAccessToken(token)
+	if err != nil {
+		return auth.Principal{}, auth.ErrInvalidToken
+	}
+	user, err := s.users.GetByID(principal.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return auth.Principal{}, auth.ErrInvalidToken
+	}
+	if err != nil {
+		return auth.Principal{}, err
+	}
+	return auth.Principal{UserID: user.ID, Username: user.Username, Role: user.Role}, nil
+}
+
+func (s *AuthService) issueTokens(user *model.User) (*dto.AuthResponse, error) {
+	principal := auth.Principal{UserID: user.ID, Username: user.Username, Role: user.Role}
+
+	accessToken, err := s.tokens.IssueAccessToken(principal)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := s.tokens.IssueRefreshToken(principal)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.AuthResponse{
+		User:         *user,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(s.tokens.AccessTTL().Seconds()),
+	}, nil
+}
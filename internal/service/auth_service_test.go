@@ -0,0 +1,71 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Cladkoewka/movie-manager/internal/auth"
+	"github.com/Cladkoewka/movie-manager/internal/model"
+	"github.com/Cladkoewka/movie-manager/internal/repository"
+	"gorm.io/gorm"
+)
+
+// fakeUserRepository keeps users in a map by ID
+type fakeUserRepository struct {
+	repository.UserRepository
+	users map[int64]model.User
+}
+
+func (r *fakeUserRepository) GetByID(id int64) (*model.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &user, nil
+}
+
+func (r *fakeUserRepository) UpdateRole(id int64, role string) (*model.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	user.Role = role
+	r.users[id] = user
+	return &user, nil
+}
+
+func TestAuthenticateUsesCurrentRole(t *testing.T) {
+	users := &fakeUserRepository{users: map[int64]model.User{
+		1: {ID: 1, Username: "root", Role: auth.RoleAdmin},
+		7: {ID: 7, Username: "ann", Role: auth.RoleEditor},
+	}}
+	tokens := auth.NewTokenManager([]byte("secret"), time.Hour, time.Hour)
+	authService := NewAuthService(users, tokens)
+	userService := NewUserService(users)
+
+	token, err := tokens.IssueAccessToken(auth.Principal{UserID: 7, Username: "ann", Role: auth.RoleEditor})
+	if err != nil {
+		t.Fatalf("IssueAccessToken: %v", err)
+	}
+	admin := auth.Principal{UserID: 1, Username: "root", Role: auth.RoleAdmin}
+	if _, err := userService.RevokeRole(admin, 7); err != nil {
+		t.Fatalf("RevokeRole: %v", err)
+	}
+
+	principal, err := authService.Authenticate(token)
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if principal.Role != auth.RoleUser || principal.Can(auth.PermissionWriteCatalog) {
+		t.Errorf("Authenticate role = %q, want the revoked role gone", principal.Role)
+	}
+
+	delete(users.users, 7)
+	if _, err := authService.Authenticate(token); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Errorf("Authenticate for a deleted user: error = %v, want ErrInvalidToken", err)
+	}
+	if _, err := authService.Authenticate("not a token"); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Errorf("Authenticate(garbage): error = %v, want ErrInvalidToken", err)
+	}
+}
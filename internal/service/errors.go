@@ -0,0 +1,52 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrGenreNotFound = errors.New("genre not found")
+	ErrGenreExists   = errors.New("genre already exists")
+
+	ErrMovieNotFound    = errors.New("movie not found")
+	ErrRevisionNotFound = errors.New("revision not found")
+	ErrPersonNotFound   = errors.New("person not found")
+	ErrCreditNotFound   = errors.New("credit not found")
+
+	ErrUserExists         = errors.New("username or email already taken")
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	ErrInvalidToken       = errors.New("invalid or expired token")
+
+	ErrUserNotFound  = errors.New("user not found")
+	ErrOwnRoleChange = errors.New("admins cannot change their own role")
+
+	ErrReviewNotFound  = errors.New("review not found")
+	ErrForbidden       = errors.New("not allowed to modify this resource")
+	ErrOwnReview       = errors.New("cannot vote on or report your own review")
+	ErrAlreadyReported = errors.New("review already reported by this user")
+)
+
+// VersionConflictError reports an update based on an outdated version of a
+// movie. CurrentVersion is what the update has to be based on instead.
+type VersionConflictError struct {
+	CurrentVersion int64
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("movie has changed, current version is %d", e.CurrentVersion)
+}
+
+// ValidationError reports a client-supplied value that cannot be accepted
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Field, e.Message)
+}
+
+func NewValidationError(field, message string) *ValidationError {
+	return &ValidationError{Field: field, Message: message}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/Cladkoewka/movie-manager/internal/model"
+	"github.com/Cladkoewka/movie-manager/internal/repository"
+	"gorm.io/gorm"
+)
+
+type GenreService struct {
+	repo repository.GenreRepository
+}
+
+func NewGenreService(repo repository.GenreRepository) *GenreService {
+	return &GenreService{repo: repo}
+}
+
+func (s *GenreService) GetAllGenres() ([]model.Genre, error) {
+	return s.repo.GetAll()
+}
+
+func (s *GenreService) GetGenreByID(id int64) (*model.Genre, error) {
+	genre, err := s.repo.GetByID(id)
+	return genre, translateGenreError(err)
+}
+
+func (s *GenreService) CreateGenre(genre model.Genre) (*model.Genre, error) {
+	if err := normalizeGenre(&genre); err != nil {
+		return nil, err
+	}
+	genre.ID = 0
+	created, err := s.repo.Create(genre)
+	return created, translateGenreError(err)
+}
+
+func (s *GenreService) UpdateGenre(genre model.Genre) (*model.Genre, error) {
+	if err := normalizeGenre(&genre); err != nil {
+		return nil, err
+	}
+	updated, err := s.repo.Update(genre)
+	return updated, translateGenreError(err)
+}
+
+func (s *GenreService) DeleteGenre(id int64) error {
+	return translateGenreError(s.repo.Delete(id))
+}
+
+func normalizeGenre(genre *model.Genre) error {
+	genre.Name = strings.TrimSpace(genre.Name)
+	if genre.Name == "" {
+		return NewValidationError("name", "must not be empty")
+	}
+	return nil
+}
+
+func translateGenreError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrGenreNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return ErrGenreExists
+	default:
+		return err
+	}
+}
@@ -3,24 +3,33 @@ package service
 import (
 	"bytes"
 	"errors"
+	"github.com/Cladkoewka/movie-manager/internal/auth"
 	"github.com/Cladkoewka/movie-manager/internal/repository"
 	"github.com/Cladkoewka/movie-manager/internal/model"
+	"gorm.io/gorm"
 	"io"
 	"mime/multipart"
 )
 
 // MoviePosterService отвечает за операции с постерами фильмов
 type MoviePosterService struct {
-	repo repository.MoviePosterRepository
+	repo      repository.MoviePosterRepository
+	movieRepo repository.MovieRepository
+	audit     *AuditService
 }
 
 // NewMoviePosterService создает новый сервис для работы с постерами фильмов
-func NewMoviePosterService(repo repository.MoviePosterRepository) *MoviePosterService {
-	return &MoviePosterService{repo: repo}
+func NewMoviePosterService(repo repository.MoviePosterRepository, movieRepo repository.MovieRepository, audit *AuditService) *MoviePosterService {
+	return &MoviePosterService{repo: repo, movieRepo: movieRepo, audit: audit}
 }
 
-// SavePoster сохраняет постер фильма в базе данных
-func (s *MoviePosterService) SavePoster(movieID int64, file multipart.File, mimeType string) error {
+// SavePoster сохраняет постер фильма в базе данных.
+// Возвращает ErrMovieNotFound, если фильма нет.
+func (s *MoviePosterService) SavePoster(actor auth.Principal, movieID int64, file multipart.File, mimeType string) error {
+	if _, err := s.movieRepo.GetMovieByID(movieID); err != nil {
+		return translateNotFound(err, ErrMovieNotFound)
+	}
+
 	// Преобразуем файл в байты
 	posterBytes, err := convertFileToBytes(file)
 	if err != nil {
@@ -28,7 +37,11 @@ func (s *MoviePosterService) SavePoster(movieID int64, file multipart.File, mime
 	}
 
 	// Сохраняем постер в базе данных
-	return s.repo.SavePoster(movieID, posterBytes, mimeType)
+	err = s.repo.SavePoster(movieID, posterBytes, mimeType, s.audit.For(actor))
+	if errors.Is(err, gorm.ErrForeignKeyViolated) {
+		return ErrMovieNotFound
+	}
+	return err
 }
 
 // GetPosterByMovieID получает постер фильма по его ID
@@ -41,8 +54,8 @@ func (s *MoviePosterService) GetPosterByMovieID(movieID int64) (*model.MoviePost
 }
 
 // DeletePoster удаляет постер фильма по его ID
-func (s *MoviePosterService) DeletePoster(movieID int64) error {
-	return s.repo.DeletePoster(movieID)
+func (s *MoviePosterService) DeletePoster(actor auth.Principal, movieID int64) error {
+	return s.repo.DeletePoster(movieID, s.audit.For(actor))
 }
 
 // Преобразует файл в байтовый массив
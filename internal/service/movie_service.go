@@ -1,28 +1,68 @@
 package service
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Cladkoewka/movie-manager/internal/auth"
 	"github.com/Cladkoewka/movie-manager/internal/constants"
+	"github.com/Cladkoewka/movie-manager/internal/diff"
+	"github.com/Cladkoewka/movie-manager/internal/mergepatch"
 	"github.com/Cladkoewka/movie-manager/internal/model"
 	"github.com/Cladkoewka/movie-manager/internal/model/dto"
 	"github.com/Cladkoewka/movie-manager/internal/repository"
+	"gorm.io/gorm"
 )
 
 type MovieService struct {
-	repo repository.MovieRepository
+	repo   repository.MovieRepository
+	genres repository.GenreRepository
+	audit  *AuditService
 }
 
-func NewMovieService(repo repository.MovieRepository) *MovieService {
-	return &MovieService{repo: repo}
+func NewMovieService(repo repository.MovieRepository, genres repository.GenreRepository, audit *AuditService) *MovieService {
+	return &MovieService{repo: repo, genres: genres, audit: audit}
 }
 
 func (s *MovieService) GetAllMovies(params dto.MovieQueryParams) (dto.MoviesResponse, error) {
-	if !constants.AllowedSortFields[params.SortBy] {
-		params.SortBy = constants.DefaultSortBy
+	if err := validateMovieQueryParams(params); err != nil {
+		return dto.MoviesResponse{}, err
+	}
+
+	if params.SearchMode == "" {
+		params.SearchMode = constants.DefaultSearchMode
+	}
+
+	if params.Match == "" {
+		params.Match = constants.DefaultMatch
+	}
+
+	if params.FuzzyThreshold == 0 {
+		params.FuzzyThreshold = constants.DefaultFuzzyThreshold
 	}
 
-	if params.OrderBy != "asc" && params.OrderBy != "desc" {
-		params.OrderBy = constants.DefaultOrderBy
+	// Relevance only exists for full-text matches, so it switches the search mode
+	// and is dropped when there is nothing to rank against
+	var sort []dto.SortField
+	for _, field := range params.Sort {
+		if field.Field == constants.SortByRelevance {
+			if params.Search == "" {
+				continue
+			}
+			params.SearchMode = constants.SearchModeFullText
+		}
+		sort = append(sort, field)
+	}
+	if len(sort) == 0 {
+		sort = []dto.SortField{{Field: constants.DefaultSortBy, Desc: constants.DefaultOrderBy == "desc"}}
 	}
+	params.Sort = sort
 
 	if params.Page <= 0 {
 		params.Page = constants.DefaultPage
@@ -31,19 +71,103 @@ func (s *MovieService) GetAllMovies(params dto.MovieQueryParams) (dto.MoviesResp
 		params.PageSize = constants.DefaultPageSize
 	}
 
-	if params.Rating != nil {
-		if *params.Rating < 0 || *params.Rating > 10 {
-			params.Rating = nil
-		}
+	if params.Cursor != "" && (params.Fuzzy || hasSortField(params.Sort, constants.SortByRelevance)) {
+		return dto.MoviesResponse{}, NewValidationError("cursor", "not supported for relevance or fuzzy ordering, use page instead")
 	}
 
 	moviesResponse, err := s.repo.GetAllMovies(params)
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		return dto.MoviesResponse{}, NewValidationError("cursor", "malformed or issued for a different sort order")
+	}
 	if err != nil {
 		return dto.MoviesResponse{}, err
 	}
 	return moviesResponse, nil
 }
 
+// validateMovieQueryParams rejects filter values that are out of range or contradict each other
+func validateMovieQueryParams(params dto.MovieQueryParams) error {
+	if params.FuzzyThreshold < 0 || params.FuzzyThreshold > 1 {
+		return NewValidationError("fuzzy_threshold", "must be between 0 and 1")
+	}
+
+	if params.SearchMode != "" && params.SearchMode != constants.SearchModeTitle && params.SearchMode != constants.SearchModeFullText {
+		return NewValidationError("search_mode", "must be 'title' or 'fulltext'")
+	}
+
+	if params.Match != "" && params.Match != constants.MatchContains && params.Match != constants.MatchExact {
+		return NewValidationError("match", "must be 'contains' or 'exact'")
+	}
+
+	seen := make(map[string]bool, len(params.Sort))
+	for _, field := range params.Sort {
+		if !constants.AllowedSortFields[field.Field] {
+			return NewValidationError("sort", fmt.Sprintf("unknown sort field %q", field.Field))
+		}
+		if seen[field.Field] {
+			return NewValidationError("sort", fmt.Sprintf("duplicate sort field %q", field.Field))
+		}
+		seen[field.Field] = true
+	}
+
+	for _, facet := range params.Facets {
+		if !constants.AllowedFacets[facet] {
+			return NewValidationError("facets", fmt.Sprintf("unknown facet %q", facet))
+		}
+	}
+
+	if params.Rating != nil && (*params.Rating < 0 || *params.Rating > 10) {
+		return NewValidationError("rating", "must be between 0 and 10")
+	}
+	if params.MaxRating != nil && (*params.MaxRating < 0 || *params.MaxRating > 10) {
+		return NewValidationError("max_rating", "must be between 0 and 10")
+	}
+	if params.Rating != nil && params.MaxRating != nil && *params.Rating > *params.MaxRating {
+		return NewValidationError("max_rating", "must not be less than rating")
+	}
+
+	if params.MinUserRating != nil && (*params.MinUserRating < 0 || *params.MinUserRating > 10) {
+		return NewValidationError("min_user_rating", "must be between 0 and 10")
+	}
+	if params.MaxUserRating != nil && (*params.MaxUserRating < 0 || *params.MaxUserRating > 10) {
+		return NewValidationError("max_user_rating", "must be between 0 and 10")
+	}
+	if params.MinUserRating != nil && params.MaxUserRating != nil && *params.MinUserRating > *params.MaxUserRating {
+		return NewValidationError("max_user_rating", "must not be less than min_user_rating")
+	}
+	if params.MinRatingCount != nil && *params.MinRatingCount < 0 {
+		return NewValidationError("min_rating_count", "must not be negative")
+	}
+
+	if params.MinDuration != nil && *params.MinDuration < 0 {
+		return NewValidationError("min_duration", "must not be negative")
+	}
+	if params.MaxDuration != nil && *params.MaxDuration < 0 {
+		return NewValidationError("max_duration", "must not be negative")
+	}
+	if params.MinDuration != nil && params.MaxDuration != nil && *params.MinDuration > *params.MaxDuration {
+		return NewValidationError("max_duration", "must not be less than min_duration")
+	}
+
+	if params.ReleasedFrom != nil && params.ReleasedTo != nil && params.ReleasedFrom.After(*params.ReleasedTo) {
+		return NewValidationError("released_to", "must not be before released_from")
+	}
+	if params.Year != nil && (*params.Year < 1 || *params.Year > 9999) {
+		return NewValidationError("year", "must be between 1 and 9999")
+	}
+
+	return nil
+}
+
+func hasSortField(sort []dto.SortField, name string) bool {
+	for _, field := range sort {
+		if field.Field == name {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *MovieService) GetMovieByID(id int64) (*model.Movie, error) {
 	movie, err := s.repo.GetMovieByID(id)
 	if err != nil {
@@ -52,26 +176,310 @@ func (s *MovieService) GetMovieByID(id int64) (*model.Movie, error) {
 	return movie, nil
 }
 
-func (s *MovieService) CreateMovie(movie model.Movie) (*model.Movie, error) {
-	newMovie, err := s.repo.CreateMovie(movie)
-	if err != nil {
+func (s *MovieService) CreateMovie(actor auth.Principal, movie model.Movie) (*model.Movie, error) {
+	if err := validateMovie(movie); err != nil {
 		return nil, err
 	}
-	return newMovie, nil
+	clearMovieTimestamps(&movie)
+	newMovie, err := s.repo.CreateMovie(movie, actorID(actor), actor.Username, s.audit.For(actor))
+	if errors.Is(err, repository.ErrGenreNotFound) {
+		return nil, NewValidationError("genres", "unknown genre id")
+	}
+	return newMovie, err
 }
 
-func (s *MovieService) UpdateMovie(movie model.Movie) (*model.Movie, error) {
-	updateMovie, err := s.repo.UpdateMovie(movie)
-	if err != nil {
+// UpdateMovie replaces the movie's fields. movie.Version is the version the
+// changes are based on; a stale one fails with a *VersionConflictError.
+func (s *MovieService) UpdateMovie(actor auth.Principal, movie model.Movie) (*model.Movie, error) {
+	if err := validateMovie(movie); err != nil {
 		return nil, err
 	}
+	clearMovieTimestamps(&movie)
+	updateMovie, err := s.repo.UpdateMovie(movie, actorID(actor), actor.Username, s.audit.For(actor))
+	if errors.Is(err, repository.ErrGenreNotFound) {
+		return nil, NewValidationError("genres", "unknown genre id")
+	}
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return nil, s.versionConflict(movie.ID)
+	}
+	if err != nil {
+		return nil, translateNotFound(err, ErrMovieNotFound)
+	}
 	return updateMovie, nil
 }
 
-func (s *MovieService) DeleteMovie(id int64) error {
-	err := s.repo.DeleteMovie(id)
+// clearMovieTimestamps drops created_at and deleted_at taken from a request
+// body. Only the database sets them, and only DeleteMovie trashes a movie.
+func clearMovieTimestamps(movie *model.Movie) {
+	movie.CreatedAt = time.Time{}
+	movie.DeletedAt = gorm.DeletedAt{}
+}
+
+// DeleteMovie moves the movie to the trash, see RestoreMovie and PurgeTrash
+func (s *MovieService) DeleteMovie(actor auth.Principal, id int64) error {
+	return translateNotFound(s.repo.DeleteMovie(id, s.audit.For(actor)), ErrMovieNotFound)
+}
+
+// PatchMovie applies a JSON Merge Patch (RFC 7396) to the movie based on
+// the given version. Fields the patch leaves out keep their values, null
+// clears a field, and genres are replaced as a whole list. The result has to
+// pass the same checks as a new movie.
+func (s *MovieService) PatchMovie(actor auth.Principal, id int64, version int64, patch []byte) (*model.Movie, error) {
+	current, err := s.repo.GetMovieByID(id)
+	if err != nil {
+		return nil, translateNotFound(err, ErrMovieNotFound)
+	}
+	if current.Version != version {
+		return nil, &VersionConflictError{CurrentVersion: current.Version}
+	}
+
+	document, err := json.Marshal(current)
 	if err != nil {
+		return nil, err
+	}
+	if err := validateMoviePatch(document, patch); err != nil {
+		return nil, err
+	}
+	patched, err := mergepatch.Apply(document, patch)
+	if err != nil {
+		return nil, NewValidationError("patch", "must be a JSON object")
+	}
+
+	var movie model.Movie
+	if err := json.Unmarshal(patched, &movie); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			return nil, NewValidationError(typeErr.Field, "must be "+jsonTypeName(typeErr.Type))
+		}
+		return nil, NewValidationError("patch", err.Error())
+	}
+	movie.ID = id
+	movie.Version = version
+	return s.UpdateMovie(actor, movie)
+}
+
+// validateMoviePatch accepts a JSON object naming only fields a movie has and
+// leaving out the ones updates cannot change
+func validateMoviePatch(document, patch []byte) error {
+	var fields, changes map[string]json.RawMessage
+	if err := json.Unmarshal(document, &fields); err != nil {
 		return err
 	}
+	if err := json.Unmarshal(patch, &changes); err != nil || changes == nil {
+		return NewValidationError("patch", "must be a JSON object")
+	}
+	for name := range changes {
+		if _, ok := fields[name]; !ok {
+			return NewValidationError(name, "unknown field")
+		}
+	}
+	for _, name := range movieComputedFields {
+		if _, ok := changes[name]; ok {
+			return NewValidationError(name, "cannot be changed")
+		}
+	}
 	return nil
 }
+
+// jsonTypeName describes the JSON value a Go type is decoded from
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "a list"
+	default:
+		return "an object"
+	}
+}
+
+// validateMovie checks the fields of a movie about to be created or saved
+func validateMovie(movie model.Movie) error {
+	if strings.TrimSpace(movie.Title) == "" {
+		return NewValidationError("title", "must not be empty")
+	}
+	if movie.ReleaseDate.IsZero() {
+		return NewValidationError("release_date", "is required")
+	}
+	if movie.Rating < 0 || movie.Rating > 10 {
+		return NewValidationError("rating", "must be between 0 and 10")
+	}
+	if movie.Duration < 0 {
+		return NewValidationError("duration", "must not be negative")
+	}
+	if movie.TrailerURL != "" {
+		trailerURL, err := url.Parse(movie.TrailerURL)
+		if err != nil || (trailerURL.Scheme != "http" && trailerURL.Scheme != "https") || trailerURL.Host == "" {
+			return NewValidationError("trailer_url", "must be an http or https URL")
+		}
+	}
+	return nil
+}
+
+// VersionMatch is the If-Match precondition of a write: the movie versions
+// it may be based on, or any version at all
+type VersionMatch struct {
+	Any      bool
+	Versions []int64
+}
+
+func (m VersionMatch) matches(version int64) bool {
+	return m.Any || slices.Contains(m.Versions, version)
+}
+
+// MatchVersion returns the version a write under the precondition is based
+// on. A single version is passed on as is; otherwise the current version is
+// used when the precondition accepts it. Writes check the version they are
+// given once more, so a change in between still fails with a
+// *VersionConflictError.
+func (s *MovieService) MatchVersion(id int64, match VersionMatch) (int64, error) {
+	if !match.Any && len(match.Versions) == 1 {
+		return match.Versions[0], nil
+	}
+	current, err := s.repo.GetMovieByID(id)
+	if err != nil {
+		return 0, translateNotFound(err, ErrMovieNotFound)
+	}
+	if !match.matches(current.Version) {
+		return 0, &VersionConflictError{CurrentVersion: current.Version}
+	}
+	return current.Version, nil
+}
+
+// versionConflict reports the version a rejected update should have been based on
+func (s *MovieService) versionConflict(id int64) error {
+	current, err := s.repo.GetMovieByID(id)
+	if err != nil {
+		return translateNotFound(err, ErrMovieNotFound)
+	}
+	return &VersionConflictError{CurrentVersion: current.Version}
+}
+
+// movieComputedFields are kept in revision snapshots but are not edited
+// through updates, so revision diffs leave them out and patches cannot set them
+var movieComputedFields = []string{"id", "version", "average_user_rating", "rating_count", "created_at", "deleted_at"}
+
+// GetRevisions lists the saved versions of a movie, newest first
+func (s *MovieService) GetRevisions(movieID int64, page, pageSize int) (dto.MovieRevisionsResponse, error) {
+	if _, err := s.repo.GetMovieByID(movieID); err != nil {
+		return dto.MovieRevisionsResponse{}, translateNotFound(err, ErrMovieNotFound)
+	}
+	revisions, total, err := s.repo.GetMovieRevisions(movieID, page, pageSize)
+	if err != nil {
+		return dto.MovieRevisionsResponse{}, err
+	}
+	return dto.MovieRevisionsResponse{Revisions: revisions, Total: total}, nil
+}
+
+// GetRevisionDiff compares a revision with the one before it
+func (s *MovieService) GetRevisionDiff(movieID int64, revision int) (dto.MovieRevisionDiff, error) {
+	_, current, err := s.getRevision(movieID, revision)
+	if err != nil {
+		return dto.MovieRevisionDiff{}, err
+	}
+
+	response := dto.MovieRevisionDiff{
+		MovieID:   movieID,
+		Revision:  current.Revision,
+		AuthorID:  current.AuthorID,
+		Author:    current.Author,
+		CreatedAt: current.CreatedAt,
+	}
+	var before interface{}
+	if revision > 1 {
+		_, previous, err := s.getRevision(movieID, revision-1)
+		if err != nil {
+			return dto.MovieRevisionDiff{}, err
+		}
+		response.PreviousRevision = &previous.Revision
+		before = previous.Snapshot
+	}
+
+	changes, err := diff.Compare(before, current.Snapshot)
+	if err != nil {
+		return dto.MovieRevisionDiff{}, err
+	}
+	for _, field := range movieComputedFields {
+		delete(changes, field)
+	}
+	response.Changes = changes
+	return response, nil
+}
+
+// RestoreRevision makes the movie look like it did in the given revision.
+// The restore is an update of its own and becomes the newest revision;
+// version is the one the restore is based on, as for UpdateMovie.
+func (s *MovieService) RestoreRevision(actor auth.Principal, movieID int64, version int64, revision int) (*model.Movie, error) {
+	_, restored, err := s.getRevision(movieID, revision)
+	if err != nil {
+		return nil, err
+	}
+	movie := restored.Snapshot
+	movie.ID = movieID
+	movie.Version = version
+	if movie.Genres, err = s.revisionGenres(movie.Genres); err != nil {
+		return nil, err
+	}
+	return s.UpdateMovie(actor, movie)
+}
+
+// revisionGenres keeps the genres of a revision that still exist by ID, so
+// renames since then apply, and looks up the deleted ones by name again. A
+// name no genre has any more brings the genre back, as for any update.
+func (s *MovieService) revisionGenres(genres []model.Genre) ([]model.Genre, error) {
+	resolved := make([]model.Genre, 0, len(genres))
+	for _, genre := range genres {
+		if genre.ID != 0 {
+			_, err := s.genres.GetByID(genre.ID)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				genre.ID = 0
+			} else if err != nil {
+				return nil, err
+			}
+		}
+		resolved = append(resolved, genre)
+	}
+	return resolved, nil
+}
+
+// getRevision loads a movie that is not in the trash together with one of its revisions
+func (s *MovieService) getRevision(movieID int64, revision int) (*model.Movie, *model.MovieRevision, error) {
+	movie, err := s.repo.GetMovieByID(movieID)
+	if err != nil {
+		return nil, nil, translateNotFound(err, ErrMovieNotFound)
+	}
+	movieRevision, err := s.repo.GetMovieRevision(movieID, revision)
+	if err != nil {
+		return nil, nil, translateNotFound(err, ErrRevisionNotFound)
+	}
+	return movie, movieRevision, nil
+}
+
+func (s *MovieService) GetTrash(page, pageSize int) (dto.MoviesResponse, error) {
+	movies, total, err := s.repo.GetDeletedMovies(page, pageSize)
+	if err != nil {
+		return dto.MoviesResponse{}, err
+	}
+	return dto.MoviesResponse{Movies: movies, Total: total}, nil
+}
+
+func (s *MovieService) RestoreMovie(actor auth.Principal, id int64) (*model.Movie, error) {
+	movie, err := s.repo.RestoreMovie(id, s.audit.For(actor))
+	if err != nil {
+		return nil, translateNotFound(err, ErrMovieNotFound)
+	}
+	return movie, nil
+}
+
+// PurgeTrash permanently removes everything that has been in the trash
+// for longer than retention. The purge is audited as made by the system.
+func (s *MovieService) PurgeTrash(retention time.Duration) (int64, error) {
+	return s.repo.PurgeDeleted(time.Now().Add(-retention), s.audit.For(auth.System))
+}
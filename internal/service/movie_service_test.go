@@ -0,0 +1,102 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Cladkoewka/movie-manager/internal/auth"
+	"github.com/Cladkoewka/movie-manager/internal/model"
+	"github.com/Cladkoewka/movie-manager/internal/model/dto"
+	"github.com/Cladkoewka/movie-manager/internal/repository"
+	"gorm.io/gorm"
+)
+
+// fakeMovieRepository serves one movie with its revisions and records the
+// last update
+type fakeMovieRepository struct {
+	repository.MovieRepository
+	movie     model.Movie
+	revisions map[int]model.MovieRevision
+	updated   *model.Movie
+}
+
+func (r *fakeMovieRepository) GetMovieByID(id int64) (*model.Movie, error) {
+	if id != r.movie.ID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	movie := r.movie
+	return &movie, nil
+}
+
+func (r *fakeMovieRepository) GetMovieRevision(movieID int64, revision int) (*model.MovieRevision, error) {
+	movieRevision, ok := r.revisions[revision]
+	if !ok || movieID != r.movie.ID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &movieRevision, nil
+}
+
+func (r *fakeMovieRepository) UpdateMovie(movie model.Movie, authorID *int64, author string, audit repository.AuditFunc) (*model.Movie, error) {
+	r.updated = &movie
+	return &movie, nil
+}
+
+// fakeGenreRepository knows genres by ID
+type fakeGenreRepository struct {
+	repository.GenreRepository
+	genres map[int64]model.Genre
+}
+
+func (r *fakeGenreRepository) GetByID(id int64) (*model.Genre, error) {
+	genre, ok := r.genres[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &genre, nil
+}
+
+func TestRestoreRevisionWithDeletedGenre(t *testing.T) {
+	snapshot := model.Movie{
+		ID:          5,
+		Title:       "Heat",
+		ReleaseDate: time.Date(1995, 12, 15, 0, 0, 0, 0, time.UTC),
+		Genres:      []model.Genre{{ID: 1, Name: "Crime"}, {ID: 2, Name: "Thriller"}},
+	}
+	movies := &fakeMovieRepository{
+		movie:     model.Movie{ID: 5, Title: "Heat (1995)", ReleaseDate: snapshot.ReleaseDate, Version: 3},
+		revisions: map[int]model.MovieRevision{1: {MovieID: 5, Revision: 1, Snapshot: snapshot}},
+	}
+	// Crime has been renamed since, Thriller deleted
+	genres := &fakeGenreRepository{genres: map[int64]model.Genre{1: {ID: 1, Name: "Crime drama"}}}
+	s := NewMovieService(movies, genres, NewAuditService(nil))
+
+	editor := auth.Principal{UserID: 2, Username: "ann", Role: auth.RoleEditor}
+	if _, err := s.RestoreRevision(editor, 5, 3, 1); err != nil {
+		t.Fatalf("RestoreRevision: %v", err)
+	}
+	if movies.updated == nil {
+		t.Fatal("RestoreRevision did not update the movie")
+	}
+	want := []model.Genre{{ID: 1, Name: "Crime"}, {Name: "Thriller"}}
+	if !reflect.DeepEqual(movies.updated.Genres, want) {
+		t.Errorf("restored genres = %+v, want %+v", movies.updated.Genres, want)
+	}
+	if movies.updated.Title != "Heat" || movies.updated.Version != 3 {
+		t.Errorf("restored %q at version %d, want %q at version 3", movies.updated.Title, movies.updated.Version, "Heat")
+	}
+}
+
+func TestValidateMovieQueryParamsSearchMode(t *testing.T) {
+	for mode, valid := range map[string]bool{"": true, "title": true, "fulltext": true, "full_text": false, "TITLE": false} {
+		err := validateMovieQueryParams(dto.MovieQueryParams{SearchMode: mode})
+		var validationErr *ValidationError
+		if valid && err != nil {
+			t.Errorf("search mode %q: unexpected error %v", mode, err)
+		}
+		if !valid && (!errors.As(err, &validationErr) || validationErr.Field != "search_mode") {
+			t.Errorf("search mode %q: error = %v, want a search_mode ValidationError", mode, err)
+		}
+	}
+}
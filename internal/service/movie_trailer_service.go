@@ -6,6 +6,7 @@ import (
 	"io"
 	"mime/multipart"
 
+	"github.com/Cladkoewka/movie-manager/internal/auth"
 	"github.com/Cladkoewka/movie-manager/internal/repository"
 	"github.com/kurin/blazer/b2"
 )
@@ -14,17 +15,19 @@ type MovieTrailerService struct {
 	movieRepository repository.MovieRepository
 	bucket *b2.Bucket
 	bucketURL string
+	audit *AuditService
 }
 
-func NewMovieTrailerService(movieRepository repository.MovieRepository, bucket *b2.Bucket, bucketURL string) *MovieTrailerService {
+func NewMovieTrailerService(movieRepository repository.MovieRepository, bucket *b2.Bucket, bucketURL string, audit *AuditService) *MovieTrailerService {
 	return &MovieTrailerService{
 		movieRepository: movieRepository,
 		bucket: bucket,
 		bucketURL: bucketURL,
+		audit: audit,
 	}
 }
 
-func (s *MovieTrailerService) UploadTrailer(movieID int64, file *multipart.FileHeader) error {
+func (s *MovieTrailerService) UploadTrailer(actor auth.Principal, movieID int64, file *multipart.FileHeader) error {
 	f, err := file.Open()
 	if err != nil {
 		return fmt.Errorf("failed to open file: %w", err)
@@ -46,21 +49,9 @@ func (s *MovieTrailerService) UploadTrailer(movieID int64, file *multipart.FileH
 
 	trailerURL := fmt.Sprintf("%s/%s", s.bucketURL, objectName)
 
-	return s.movieRepository.UpdateMovieTrailer(movieID, trailerURL)
+	return s.movieRepository.UpdateMovieTrailer(movieID, trailerURL, actorID(actor), actor.Username, s.audit.For(actor))
 }
 
-func (s *MovieTrailerService) SetTrailerURL(movieID int64, trailerURL string) error {
-	movie, err := s.movieRepository.GetMovieByID(movieID)
-	if err != nil {
-		return err
-	}
-
-	movie.TrailerURL = trailerURL
-
-	_, err = s.movieRepository.UpdateMovie(*movie)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
\ No newline at end of file
+func (s *MovieTrailerService) SetTrailerURL(actor auth.Principal, movieID int64, trailerURL string) error {
+	return s.movieRepository.UpdateMovieTrailer(movieID, trailerURL, actorID(actor), actor.Username, s.audit.For(actor))
+}
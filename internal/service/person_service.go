@@ -0,0 +1,116 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/Cladkoewka/movie-manager/internal/constants"
+	"github.com/Cladkoewka/movie-manager/internal/model"
+	"github.com/Cladkoewka/movie-manager/internal/model/dto"
+	"github.com/Cladkoewka/movie-manager/internal/repository"
+	"gorm.io/gorm"
+)
+
+type PersonService struct {
+	repo      repository.PersonRepository
+	movieRepo repository.MovieRepository
+}
+
+func NewPersonService(repo repository.PersonRepository, movieRepo repository.MovieRepository) *PersonService {
+	return &PersonService{repo: repo, movieRepo: movieRepo}
+}
+
+func (s *PersonService) GetAllPeople(search string) ([]model.Person, error) {
+	return s.repo.GetAll(strings.TrimSpace(search))
+}
+
+func (s *PersonService) GetPersonByID(id int64) (*model.Person, error) {
+	person, err := s.repo.GetByID(id)
+	return person, translateNotFound(err, ErrPersonNotFound)
+}
+
+func (s *PersonService) CreatePerson(person model.Person) (*model.Person, error) {
+	if err := normalizePerson(&person); err != nil {
+		return nil, err
+	}
+	person.ID = 0
+	return s.repo.Create(person)
+}
+
+func (s *PersonService) UpdatePerson(person model.Person) (*model.Person, error) {
+	if err := normalizePerson(&person); err != nil {
+		return nil, err
+	}
+	updated, err := s.repo.Update(person)
+	return updated, translateNotFound(err, ErrPersonNotFound)
+}
+
+func (s *PersonService) DeletePerson(id int64) error {
+	return translateNotFound(s.repo.Delete(id), ErrPersonNotFound)
+}
+
+// GetFilmography returns every credit of the person together with the movie
+func (s *PersonService) GetFilmography(personID int64) (*dto.Filmography, error) {
+	person, err := s.GetPersonByID(personID)
+	if err != nil {
+		return nil, err
+	}
+	credits, err := s.repo.GetCreditsByPersonID(personID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.Filmography{Person: *person, Credits: credits}, nil
+}
+
+func (s *PersonService) GetMovieCredits(movieID int64) ([]model.MovieCredit, error) {
+	if _, err := s.movieRepo.GetMovieByID(movieID); err != nil {
+		return nil, translateNotFound(err, ErrMovieNotFound)
+	}
+	return s.repo.GetCreditsByMovieID(movieID)
+}
+
+func (s *PersonService) AddMovieCredit(credit model.MovieCredit) (*model.MovieCredit, error) {
+	credit.Role = strings.ToLower(strings.TrimSpace(credit.Role))
+	if !constants.AllowedCreditRoles[credit.Role] {
+		return nil, NewValidationError("role", "unknown credit role")
+	}
+	if credit.BillingOrder < 0 {
+		return nil, NewValidationError("billing_order", "must not be negative")
+	}
+	credit.CharacterName = strings.TrimSpace(credit.CharacterName)
+
+	if _, err := s.movieRepo.GetMovieByID(credit.MovieID); err != nil {
+		return nil, translateNotFound(err, ErrMovieNotFound)
+	}
+	if _, err := s.repo.GetByID(credit.PersonID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NewValidationError("person_id", "person does not exist")
+		}
+		return nil, err
+	}
+
+	credit.ID = 0
+	credit.Movie = nil
+	credit.Person = nil
+	return s.repo.CreateCredit(credit)
+}
+
+func (s *PersonService) DeleteMovieCredit(movieID, creditID int64) error {
+	return translateNotFound(s.repo.DeleteCredit(movieID, creditID), ErrCreditNotFound)
+}
+
+func normalizePerson(person *model.Person) error {
+	person.Name = strings.TrimSpace(person.Name)
+	if person.Name == "" {
+		return NewValidationError("name", "must not be empty")
+	}
+	return nil
+}
+
+// translateNotFound replaces gorm's not-found error with the service error for the entity
+func translateNotFound(err error, notFound error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return notFound
+	}
+	return err
+}
@@ -1,26 +1,310 @@
 package service
 
 import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Cladkoewka/movie-manager/internal/auth"
+	"github.com/Cladkoewka/movie-manager/internal/constants"
 	"github.com/Cladkoewka/movie-manager/internal/model"
+	"github.com/Cladkoewka/movie-manager/internal/model/dto"
+	"github.com/Cladkoewka/movie-manager/internal/moderation"
 	"github.com/Cladkoewka/movie-manager/internal/repository"
+	"gorm.io/gorm"
 )
 
 type ReviewService struct {
-	repo repository.ReviewRepository
+	repo            repository.ReviewRepository
+	movieRepo       repository.MovieRepository
+	moderation      *moderation.Chain
+	reportThreshold int
+	audit           *AuditService
+}
+
+// NewReviewService creates the service. Reviews reported by reportThreshold
+// users go back to the moderation queue.
+func NewReviewService(repo repository.ReviewRepository, movieRepo repository.MovieRepository, moderationChain *moderation.Chain, reportThreshold int, audit *AuditService) *ReviewService {
+	return &ReviewService{repo: repo, movieRepo: movieRepo, moderation: moderationChain, reportThreshold: reportThreshold, audit: audit}
+}
+
+func (s *ReviewService) GetByMovieID(movieID int64, params dto.ReviewQueryParams) (dto.ReviewsResponse, error) {
+	if !constants.AllowedReviewSorts[params.Sort] {
+		return dto.ReviewsResponse{}, NewValidationError("sort", fmt.Sprintf("unknown sort %q", params.Sort))
+	}
+
+	response, err := s.repo.GetByMovieID(movieID, params)
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		return dto.ReviewsResponse{}, NewValidationError("cursor", "malformed or issued for a different sort order")
+	}
+	return response, err
+}
+
+// CreateReview stores a review on behalf of actor. The author is taken from
+// review.UserID, which is empty for imported reviews.
+func (s *ReviewService) CreateReview(actor auth.Principal, review model.Review) (*model.Review, error) {
+	if err := normalizeReview(&review); err != nil {
+		return nil, err
+	}
+	review.ID = 0
+	if _, err := s.movieRepo.GetMovieByID(review.MovieID); err != nil {
+		return nil, translateNotFound(err, ErrMovieNotFound)
+	}
+	if err := s.moderate(&review); err != nil {
+		return nil, err
+	}
+	created, err := s.repo.Create(review, s.audit.For(actor))
+	// The movie may have been moved to the trash since the check above
+	if errors.Is(err, repository.ErrMovieNotFound) {
+		return nil, ErrMovieNotFound
+	}
+	return created, err
+}
+
+// ReplyToReview adds a reply below a published review or reply. Replies
+// belong to the parent's movie, carry no score and nest up to
+// constants.MaxReplyDepth levels.
+func (s *ReviewService) ReplyToReview(author auth.Principal, parentID int64, comment string) (*model.Review, error) {
+	parent, err := s.repo.GetByID(parentID)
+	if err != nil {
+		return nil, translateNotFound(err, ErrReviewNotFound)
+	}
+	if parent.Status != constants.ReviewStatusApproved || parent.Deleted {
+		return nil, ErrReviewNotFound
+	}
+	if parent.Depth >= constants.MaxReplyDepth {
+		return nil, NewValidationError("parent_id", fmt.Sprintf("replies can nest at most %d levels", constants.MaxReplyDepth))
+	}
+
+	reply := model.Review{
+		MovieID:  parent.MovieID,
+		UserID:   &author.UserID,
+		ParentID: &parent.ID,
+		Depth:    parent.Depth + 1,
+		Comment:  comment,
+	}
+	if err := normalizeReview(&reply); err != nil {
+		return nil, err
+	}
+	if err := s.moderate(&reply); err != nil {
+		return nil, err
+	}
+	created, err := s.repo.Create(reply, s.audit.For(author))
+	return created, reviewWriteError(err)
+}
+
+// GetThread returns a published review with its approved replies. Replies
+// below a hidden reply are left out with it.
+func (s *ReviewService) GetThread(id int64) (*dto.ReviewThread, error) {
+	reviews, err := s.repo.GetThread(id)
+	if err != nil {
+		return nil, translateNotFound(err, ErrReviewNotFound)
+	}
+
+	children := make(map[int64][]model.Review)
+	var root *model.Review
+	for i, review := range reviews {
+		if review.ID == id {
+			root = &reviews[i]
+		} else if review.ParentID != nil && review.Status == constants.ReviewStatusApproved {
+			children[*review.ParentID] = append(children[*review.ParentID], review)
+		}
+	}
+	if root == nil || root.Status != constants.ReviewStatusApproved {
+		return nil, ErrReviewNotFound
+	}
+
+	thread := buildThread(*root, children)
+	return &thread, nil
+}
+
+func buildThread(review model.Review, children map[int64][]model.Review) dto.ReviewThread {
+	replies := children[review.ID]
+	sort.Slice(replies, func(i, j int) bool {
+		if !replies[i].CreatedAt.Equal(replies[j].CreatedAt) {
+			return replies[i].CreatedAt.Before(replies[j].CreatedAt)
+		}
+		return replies[i].ID < replies[j].ID
+	})
+
+	thread := dto.ReviewThread{Review: review, Replies: make([]dto.ReviewThread, 0, len(replies))}
+	for _, reply := range replies {
+		thread.Replies = append(thread.Replies, buildThread(reply, children))
+	}
+	return thread
+}
+
+// UpdateReview replaces the comment and score of a review written by the actor
+func (s *ReviewService) UpdateReview(actor auth.Principal, id int64, request dto.ReviewUpdateRequest) (*model.Review, error) {
+	review, err := s.authorizedReview(actor, id)
+	if err != nil {
+		return nil, err
+	}
+	before := *review
+	review.Comment = request.Comment
+	review.Score = request.Score
+	if err := normalizeReview(review); err != nil {
+		return nil, err
+	}
+
+	rejectedByModerator := review.Status == constants.ReviewStatusRejected && review.ModeratedBy != nil
+	if err := s.moderate(review); err != nil {
+		return nil, err
+	}
+	// Editing must not get a review past a moderator: the filters may hold
+	// or reject it, but only a moderator approves a queued or reported one
+	if review.Status == constants.ReviewStatusApproved {
+		switch {
+		case rejectedByModerator:
+			review.Status = constants.ReviewStatusPending
+			review.ModerationReason = "edited after being rejected by a moderator"
+		case before.Status == constants.ReviewStatusPending:
+			review.Status = constants.ReviewStatusPending
+			review.ModerationReason = before.ModerationReason
+		case review.ReportCount >= s.reportThreshold:
+			review.Status = constants.ReviewStatusPending
+			review.ModerationReason = fmt.Sprintf("reported by %d users", review.ReportCount)
+		}
+	}
+
+	updated, err := s.repo.Update(*review, s.audit.For(actor))
+	return updated, reviewWriteError(err)
+}
+
+func (s *ReviewService) DeleteReview(actor auth.Principal, id int64) error {
+	if _, err := s.authorizedReview(actor, id); err != nil {
+		return err
+	}
+	return reviewWriteError(s.repo.Delete(id, s.audit.For(actor)))
+}
+
+// VoteReview marks a published review as helpful or unhelpful. A second
+// vote by the same user replaces the first.
+func (s *ReviewService) VoteReview(voter auth.Principal, id int64, helpful bool) (*model.Review, error) {
+	if _, err := s.othersPublishedReview(voter, id); err != nil {
+		return nil, err
+	}
+	review, err := s.repo.Vote(model.ReviewVote{ReviewID: id, UserID: voter.UserID, Helpful: helpful})
+	return review, translateNotFound(err, ErrReviewNotFound)
+}
+
+// ReportReview flags a published review as abusive
+func (s *ReviewService) ReportReview(reporter auth.Principal, id int64, reason string) error {
+	if _, err := s.othersPublishedReview(reporter, id); err != nil {
+		return err
+	}
+	_, err := s.repo.Report(model.ReviewReport{
+		ReviewID: id,
+		UserID:   reporter.UserID,
+		Reason:   strings.TrimSpace(reason),
+	}, s.reportThreshold, s.audit.For(reporter))
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrAlreadyReported
+	}
+	return reviewWriteError(err)
+}
+
+// othersPublishedReview loads an approved review written by someone else.
+// Hidden reviews are reported as missing.
+func (s *ReviewService) othersPublishedReview(actor auth.Principal, id int64) (*model.Review, error) {
+	review, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, translateNotFound(err, ErrReviewNotFound)
+	}
+	if review.Status != constants.ReviewStatusApproved || review.Deleted {
+		return nil, ErrReviewNotFound
+	}
+	if review.UserID != nil && *review.UserID == actor.UserID {
+		return nil, ErrOwnReview
+	}
+	return review, nil
+}
+
+// GetModerationQueue lists reviews in the given state, oldest first
+func (s *ReviewService) GetModerationQueue(status string, page, pageSize int) (dto.ModerationQueueResponse, error) {
+	if !constants.AllowedReviewStatuses[status] {
+		return dto.ModerationQueueResponse{}, NewValidationError("status", fmt.Sprintf("unknown status %q", status))
+	}
+	reviews, total, err := s.repo.GetByStatus(status, page, pageSize)
+	if err != nil {
+		return dto.ModerationQueueResponse{}, err
+	}
+	return dto.ModerationQueueResponse{Reviews: reviews, Total: total}, nil
+}
+
+// ApproveReview publishes a review regardless of what the filters decided
+func (s *ReviewService) ApproveReview(moderator auth.Principal, id int64, reason string) (*model.Review, error) {
+	return s.setStatus(moderator, id, constants.ReviewStatusApproved, reason)
+}
+
+func (s *ReviewService) RejectReview(moderator auth.Principal, id int64, reason string) (*model.Review, error) {
+	return s.setStatus(moderator, id, constants.ReviewStatusRejected, reason)
+}
+
+func (s *ReviewService) setStatus(moderator auth.Principal, id int64, status, reason string) (*model.Review, error) {
+	review, err := s.repo.SetStatus(id, status, strings.TrimSpace(reason), moderator.UserID, s.audit.For(moderator))
+	return review, reviewWriteError(err)
+}
+
+// reviewWriteError maps the errors of writes to an existing review. A
+// review whose movie is in the trash is in the trash with it.
+func reviewWriteError(err error) error {
+	if errors.Is(err, repository.ErrMovieNotFound) {
+		return ErrReviewNotFound
+	}
+	return translateNotFound(err, ErrReviewNotFound)
 }
 
-func NewReviewService(repo repository.ReviewRepository) *ReviewService {
-	return &ReviewService{repo: repo}
+// moderate runs the filter chain and stores its decision on the review
+func (s *ReviewService) moderate(review *model.Review) error {
+	decision, err := s.moderation.Evaluate(moderation.Submission{
+		ReviewID: review.ID,
+		UserID:   review.UserID,
+		MovieID:  review.MovieID,
+		Comment:  review.Comment,
+	})
+	if err != nil {
+		return err
+	}
+	review.Status = decision.Status()
+	review.ModerationReason = decision.Reason()
+	return nil
 }
 
-func (s *ReviewService) GetAllByMovieID(movieID int64) ([]model.Review, error) {
-	return s.repo.GetAllByMovieID(movieID)
+// authorizedReview loads a review the actor may change: their own, or any
+// review when the actor is a moderator. Tombstones cannot be changed.
+func (s *ReviewService) authorizedReview(actor auth.Principal, id int64) (*model.Review, error) {
+	review, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, translateNotFound(err, ErrReviewNotFound)
+	}
+	if review.Deleted {
+		return nil, ErrReviewNotFound
+	}
+	if !canModifyReview(actor, review) {
+		return nil, ErrForbidden
+	}
+	return review, nil
 }
 
-func (s *ReviewService) CreateReview(review model.Review) (*model.Review, error) {
-	return s.repo.Create(review)
+func canModifyReview(actor auth.Principal, review *model.Review) bool {
+	if actor.Can(auth.PermissionModerateReviews) {
+		return true
+	}
+	return review.UserID != nil && *review.UserID == actor.UserID
 }
 
-func (s *ReviewService) DeleteReview(id int64) error {
-	return s.repo.Delete(id)
+func normalizeReview(review *model.Review) error {
+	review.Comment = strings.TrimSpace(review.Comment)
+	if review.Comment == "" {
+		return NewValidationError("comment", "must not be empty")
+	}
+	if review.ParentID != nil && review.Score != nil {
+		return NewValidationError("score", "replies cannot have a score")
+	}
+	if review.Score != nil && (*review.Score < constants.MinReviewScore || *review.Score > constants.MaxReviewScore) {
+		return NewValidationError("score", fmt.Sprintf("must be between %d and %d", constants.MinReviewScore, constants.MaxReviewScore))
+	}
+	return nil
 }
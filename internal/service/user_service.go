@@ -0,0 +1,50 @@
+package service
+
+import (
+	"github.com/Cladkoewka/movie-manager/internal/auth"
+	"github.com/Cladkoewka/movie-manager/internal/model"
+	"github.com/Cladkoewka/movie-manager/internal/repository"
+)
+
+// UserService manages accounts on behalf of administrators
+type UserService struct {
+	users repository.UserRepository
+}
+
+func NewUserService(users repository.UserRepository) *UserService {
+	return &UserService{users: users}
+}
+
+func (s *UserService) GetAllUsers() ([]model.User, error) {
+	return s.users.GetAll()
+}
+
+// GrantRole gives the user a new role. Admins cannot change their own role,
+// so the last admin cannot lock everyone out by accident.
+func (s *UserService) GrantRole(actor auth.Principal, userID int64, role string) (*model.User, error) {
+	if !auth.IsValidRole(role) {
+		return nil, NewValidationError("role", "unknown role")
+	}
+	if actor.UserID == userID {
+		return nil, ErrOwnRoleChange
+	}
+	user, err := s.users.UpdateRole(userID, role)
+	return user, translateNotFound(err, ErrUserNotFound)
+}
+
+// RevokeRole returns the user to the default role
+func (s *UserService) RevokeRole(actor auth.Principal, userID int64) (*model.User, error) {
+	return s.GrantRole(actor, userID, auth.RoleUser)
+}
+
+// GrantRoleByUsername is used from the command line to bootstrap the first admin
+func (s *UserService) GrantRoleByUsername(username, role string) (*model.User, error) {
+	if !auth.IsValidRole(role) {
+		return nil, NewValidationError("role", "unknown role")
+	}
+	user, err := s.users.GetByUsername(username)
+	if err != nil {
+		return nil, translateNotFound(err, ErrUserNotFound)
+	}
+	return s.users.UpdateRole(user.ID, role)
+}
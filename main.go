@@ -4,6 +4,10 @@
 // @host localhost:8080
 // @BasePath /
 // @schemes http
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Access token from /auth/login as "Bearer <token>"
 package main
 
 import (
@@ -15,9 +19,14 @@ import (
 	"github.com/gin-contrib/cors"
 
 	_ "github.com/Cladkoewka/movie-manager/docs"
+	"github.com/Cladkoewka/movie-manager/internal/auth"
 	"github.com/Cladkoewka/movie-manager/internal/cache"
+	"github.com/Cladkoewka/movie-manager/internal/config"
 	"github.com/Cladkoewka/movie-manager/internal/handler"
+	"github.com/Cladkoewka/movie-manager/internal/jobs"
 	"github.com/Cladkoewka/movie-manager/internal/loader"
+	"github.com/Cladkoewka/movie-manager/internal/middleware"
+	"github.com/Cladkoewka/movie-manager/internal/moderation"
 	"github.com/Cladkoewka/movie-manager/internal/model"
 	"github.com/Cladkoewka/movie-manager/internal/repository"
 	"github.com/Cladkoewka/movie-manager/internal/service"
@@ -31,11 +40,13 @@ import (
 var (
 	shouldMigrate bool
 	shouldLoadInitialData bool
+	grantAdminUsername string
 )
 
 func init() {
 	flag.BoolVar(&shouldMigrate, "migrate", false, "Run database migrations")
 	flag.BoolVar(&shouldLoadInitialData, "load", false, "Load initial data from JSON")
+	flag.StringVar(&grantAdminUsername, "grant-admin", "", "Grant the admin role to an existing user")
 	flag.Parse()
 }
 
@@ -49,45 +60,124 @@ func main() {
 
 	//bucket, bucketURL := initB2()
 	
-	cacheService := cache.NewRedisService()
+	cfg := initConfig()
+	cacheService := initCache(cfg)
+	tokenManager := initTokenManager(cfg)
 
-	reviewRepository := repository.NewReviewRepository(db)
-	reviewService := service.NewReviewService(reviewRepository)
-	reviewHandler := handler.NewReviewHandler(reviewService)
+	auditRepository := repository.NewAuditRepository(db)
+	auditService := service.NewAuditService(auditRepository)
+	auditHandler := handler.NewAuditHandler(auditService)
 	movieRepository := repository.NewMovieRepository(db, cacheService)
-	movieService := service.NewMovieService(movieRepository)
+	reviewRepository := repository.NewReviewRepository(db, cacheService)
+	moderationChain := initModeration(cfg, reviewRepository)
+	reviewService := service.NewReviewService(reviewRepository, movieRepository, moderationChain, cfg.ModerationReportThreshold, auditService)
+	reviewHandler := handler.NewReviewHandler(reviewService)
+	moderationHandler := handler.NewModerationHandler(reviewService)
+	genreRepository := repository.NewGenreRepository(db, cacheService)
+	movieService := service.NewMovieService(movieRepository, genreRepository, auditService)
 	moviePosterRepository := repository.NewMoviePosterRepository(db)
-	moviePosterService := service.NewMoviePosterService(moviePosterRepository)
+	moviePosterService := service.NewMoviePosterService(moviePosterRepository, movieRepository, auditService)
 	movieHandler := handler.NewMovieHandler(movieService, moviePosterService)
-	//movieTrailerService := service.NewMovieTrailerService(movieRepository, bucket, bucketURL)
+	genreService := service.NewGenreService(genreRepository)
+	genreHandler := handler.NewGenreHandler(genreService)
+	personRepository := repository.NewPersonRepository(db)
+	personService := service.NewPersonService(personRepository, movieRepository)
+	personHandler := handler.NewPersonHandler(personService)
+	userRepository := repository.NewUserRepository(db)
+	authService := service.NewAuthService(userRepository, tokenManager)
+	authHandler := handler.NewAuthHandler(authService)
+	userService := service.NewUserService(userRepository)
+	userHandler := handler.NewUserHandler(userService)
+	//movieTrailerService := service.NewMovieTrailerService(movieRepository, bucket, bucketURL, auditService)
 	//movieTrailerHandler := handler.NewMovieTrailerHandler(movieTrailerService)
 
 	if shouldLoadInitialData {
-		loadInitialData(movieService, reviewService)
+		loadInitialData(db, movieService, reviewService)
+	}
+
+	if grantAdminUsername != "" {
+		grantAdmin(userService, grantAdminUsername)
 	}
 
+	jobs.StartTrashPurge(movieService, cfg.TrashRetention, cfg.TrashPurgeInterval)
+
 	r := gin.Default()
 	
-	r.Use(cors.Default())
+	r.Use(cors.New(corsConfig()))
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	r.POST("/auth/register", authHandler.Register)
+	r.POST("/auth/login", authHandler.Login)
+	r.POST("/auth/refresh", authHandler.Refresh)
+
 	r.GET("/movies", movieHandler.GetAllMovies)
 	r.GET("/movies/:id", movieHandler.GetMovieByID)
-	r.POST("/movies", movieHandler.CreateMovie)
-	r.PUT("/movies/:id", movieHandler.UpdateMovie)
-	r.DELETE("/movies/:id", movieHandler.DeleteMovie)
-	r.POST("/movies/:id/poster", movieHandler.UploadPoster)
 	r.GET("/movies/:id/poster", movieHandler.GetPoster)
-	//r.POST("/movies/:id/trailer", movieTrailerHandler.UploadTrailer)
-	//r.PUT("/movies/:id/trailer", movieTrailerHandler.SetTrailerUrl)
+	r.GET("/movies/:id/credits", personHandler.GetMovieCredits)
+	r.GET("/people", personHandler.GetAllPeople)
+	r.GET("/people/:id", personHandler.GetPersonByID)
+	r.GET("/people/:id/filmography", personHandler.GetFilmography)
+	r.GET("/genres", genreHandler.GetAllGenres)
+	r.GET("/genres/:id", genreHandler.GetGenreByID)
 	r.GET("/reviews/movie/:movie_id", reviewHandler.GetReviewsByMovieID)
-	r.POST("/reviews", reviewHandler.CreateReview)
-	r.DELETE("/reviews/:id", reviewHandler.DeleteReview)
+	r.GET("/reviews/:id/thread", reviewHandler.GetThread)
+
+	// Everything that changes data requires a signed-in user
+	authorized := r.Group("/", middleware.RequireAuth(authService))
+	authorized.POST("/reviews", middleware.RequirePermission(auth.PermissionWriteReviews), reviewHandler.CreateReview)
+	authorized.PUT("/reviews/:id", middleware.RequirePermission(auth.PermissionWriteReviews), reviewHandler.UpdateReview)
+	authorized.DELETE("/reviews/:id", middleware.RequirePermission(auth.PermissionWriteReviews), reviewHandler.DeleteReview)
+	authorized.POST("/reviews/:id/replies", middleware.RequirePermission(auth.PermissionWriteReviews), reviewHandler.ReplyToReview)
+	authorized.POST("/reviews/:id/vote", middleware.RequirePermission(auth.PermissionWriteReviews), reviewHandler.VoteReview)
+	authorized.POST("/reviews/:id/report", middleware.RequirePermission(auth.PermissionWriteReviews), reviewHandler.ReportReview)
+
+	editors := authorized.Group("/", middleware.RequirePermission(auth.PermissionWriteCatalog))
+	editors.POST("/movies", movieHandler.CreateMovie)
+	editors.PUT("/movies/:id", movieHandler.UpdateMovie)
+	editors.PATCH("/movies/:id", movieHandler.PatchMovie)
+	editors.DELETE("/movies/:id", movieHandler.DeleteMovie)
+	editors.GET("/movies/trash", movieHandler.GetTrash)
+	editors.POST("/movies/:id/restore", movieHandler.RestoreMovie)
+	editors.GET("/movies/:id/revisions", movieHandler.GetRevisions)
+	editors.GET("/movies/:id/revisions/:rev/diff", movieHandler.GetRevisionDiff)
+	editors.POST("/movies/:id/revisions/:rev/restore", movieHandler.RestoreRevision)
+	editors.POST("/movies/:id/poster", movieHandler.UploadPoster)
+	//editors.POST("/movies/:id/trailer", movieTrailerHandler.UploadTrailer)
+	//editors.PUT("/movies/:id/trailer", movieTrailerHandler.SetTrailerUrl)
+	editors.POST("/movies/:id/credits", personHandler.AddMovieCredit)
+	editors.DELETE("/movies/:id/credits/:credit_id", personHandler.DeleteMovieCredit)
+	editors.POST("/people", personHandler.CreatePerson)
+	editors.PUT("/people/:id", personHandler.UpdatePerson)
+	editors.DELETE("/people/:id", personHandler.DeletePerson)
+	editors.POST("/genres", genreHandler.CreateGenre)
+	editors.PUT("/genres/:id", genreHandler.UpdateGenre)
+	editors.DELETE("/genres/:id", genreHandler.DeleteGenre)
 
+	moderators := authorized.Group("/moderation", middleware.RequirePermission(auth.PermissionModerateReviews))
+	moderators.GET("/reviews", moderationHandler.GetQueue)
+	moderators.POST("/reviews/:id/approve", moderationHandler.ApproveReview)
+	moderators.POST("/reviews/:id/reject", moderationHandler.RejectReview)
+
+	admins := authorized.Group("/admin", middleware.RequirePermission(auth.PermissionManageRoles))
+	admins.GET("/users", userHandler.GetAllUsers)
+	admins.PUT("/users/:id/role", userHandler.GrantRole)
+	admins.DELETE("/users/:id/role", userHandler.RevokeRole)
+
+	authorized.GET("/audit", middleware.RequirePermission(auth.PermissionReadAudit), auditHandler.GetAuditLog)
 
 	startServer(r)
 }
 
+// corsConfig allows any origin, like cors.Default, and lets browsers send
+// the headers the API reads and read the ETag it returns
+func corsConfig() cors.Config {
+	corsCfg := cors.DefaultConfig()
+	corsCfg.AllowAllOrigins = true
+	corsCfg.AddAllowHeaders("Authorization", "If-Match")
+	corsCfg.AddExposeHeaders("ETag")
+	return corsCfg
+}
+
 func initDB() *gorm.DB {
 	db, err := repository.NewDBConnection()
 	if err != nil {
@@ -96,16 +186,93 @@ func initDB() *gorm.DB {
 	return db
 }
 
+func initConfig() *config.Config {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Error loading config: %v", err)
+	}
+	return cfg
+}
+
+func initCache(cfg *config.Config) cache.Cache {
+	cacheService, err := cache.New(cfg)
+	if err != nil {
+		log.Fatalf("Error initializing cache: %v", err)
+	}
+	return cacheService
+}
+
+func initTokenManager(cfg *config.Config) *auth.TokenManager {
+	// A per-process random secret would log everyone out on every restart
+	// and make replicas reject each other's tokens
+	if cfg.JWTSecret == "" {
+		log.Fatal("JWT_SECRET must be set")
+	}
+	return auth.NewTokenManager([]byte(cfg.JWTSecret), cfg.JWTAccessTTL, cfg.JWTRefreshTTL)
+}
+
+// initModeration builds the filter chain every new or edited review passes through
+func initModeration(cfg *config.Config, history moderation.ReviewHistory) *moderation.Chain {
+	return moderation.NewChain(
+		moderation.NewBannedWordsFilter(cfg.ModerationBannedWords),
+		moderation.NewLinkSpamFilter(cfg.ModerationMaxLinks),
+		moderation.NewDuplicateFilter(history),
+		moderation.NewRateFilter(history, cfg.ModerationRateLimit, cfg.ModerationRateWindow),
+	)
+}
+
 func runMigrations(db *gorm.DB) {
+	if err := db.AutoMigrate(&model.Genre{}); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
 	if err := db.AutoMigrate(&model.Movie{}); err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
+	if err := repository.RemoveMovieOrphans(db); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
 	if err := db.AutoMigrate(&model.MoviePoster{}); err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
 	if err := db.AutoMigrate(&model.Review{}); err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
+	if err := db.AutoMigrate(&model.MovieRevision{}); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+	if err := db.AutoMigrate(&model.Person{}, &model.MovieCredit{}); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+	if err := db.AutoMigrate(&model.User{}); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+	if err := db.AutoMigrate(&model.ReviewVote{}, &model.ReviewReport{}); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+	if err := db.AutoMigrate(&model.AuditEntry{}); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+	if err := repository.MigrateMovieSearch(db); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+	if err := repository.MigrateMovieTrigram(db); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+	if err := repository.MigrateGenreIndexes(db); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+	if err := repository.MigrateLegacyGenres(db); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+	if err := repository.MigrateAuditLog(db); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+	if err := repository.BackfillDirectorCredits(db); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+	if err := repository.RecomputeMovieRatings(db); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
 }
 
 func initB2() (*b2.Bucket, string) {
@@ -127,7 +294,10 @@ func initB2() (*b2.Bucket, string) {
 	return bucket, bucketURL
 }
 
-func loadInitialData(movieService *service.MovieService, reviewService *service.ReviewService) {
+func loadInitialData(db *gorm.DB, movieService *service.MovieService, reviewService *service.ReviewService) {
+	if err := repository.MigrateLegacyGenres(db); err != nil {
+		log.Fatal("Failed to migrate legacy genres:", err)
+	}
 	err := loader.LoadMoviesFromJSON(movieService, "movies_dump.json")
 	if err != nil {
 	log.Fatal("Failed to load movies from JSON:", err)
@@ -135,6 +305,16 @@ func loadInitialData(movieService *service.MovieService, reviewService *service.
 	if err := loader.LoadReviewsFromJSON(reviewService, "reviews_dump.json"); err != nil {
 		log.Fatal("Failed to load reviews from JSON:", err)
 	}
+	if err := repository.BackfillDirectorCredits(db); err != nil {
+		log.Fatal("Failed to backfill director credits:", err)
+	}
+}
+
+func grantAdmin(userService *service.UserService, username string) {
+	if _, err := userService.GrantRoleByUsername(username, auth.RoleAdmin); err != nil {
+		log.Fatalf("Failed to grant admin role to %s: %v", username, err)
+	}
+	log.Printf("Granted admin role to %s", username)
 }
 
 func startServer(r *gin.Engine) {